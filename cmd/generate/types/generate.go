@@ -157,6 +157,34 @@ func (v View) ToGoArgName(str string) string {
 	return str
 }
 
+// ownedTypes are the Godot builtins holding engine memory that must be
+// destroyed, values of these types created from Go are owned by the caller
+var ownedTypes = map[string]bool{
+	"Variant":          true,
+	"Array":            true,
+	"Dictionary":       true,
+	"NodePath":         true,
+	"PoolByteArray":    true,
+	"PoolIntArray":     true,
+	"PoolRealArray":    true,
+	"PoolStringArray":  true,
+	"PoolVector2Array": true,
+	"PoolVector3Array": true,
+	"PoolColorArray":   true,
+}
+
+// IsOwnedType returns true if the given Go type name is a Godot builtin that
+// must be freed when created from Go
+func (v View) IsOwnedType(goType string) bool {
+	return ownedTypes[goType]
+}
+
+// IsDestroyMethod returns true if the given method is the destructor of the
+// given type definition
+func (v View) IsDestroyMethod(typeDef TypeDef, method Method) bool {
+	return method.Name == typeDef.Name+"_destroy"
+}
+
 // IsBasicType returns true if the given string is part of our defined basic types
 func (v View) IsBasicType(str string) bool {
 	switch str {
@@ -33,6 +33,7 @@ import (
 // that is passed to the gdnative_wrapper.go.tmpl template for filling
 type RegistryData struct {
 	Package string
+	Imports []string
 	Classes map[string]gdnative.Registrable
 }
 
@@ -72,6 +73,7 @@ func (cmd *generateCmd) Run(ctx *context) error {
 		for className, classData := range registrable {
 			data.Classes[className] = classData
 		}
+		data.Imports = gdnative.LookupImports(p, registrable)
 
 		// create a template from the template file
 		tpl, tplErr := template.ParseFiles(tplPath)
@@ -11,9 +11,10 @@ type SimpleClass struct {
 	base gdnative.Object
 }
 
-// Instances is a map of our created Godot classes. This will be populated when
-// Godot calls the CreateFunc.
-var Instances = map[string]*SimpleClass{}
+// Instances is a registry of our created Godot classes. This will be populated when
+// Godot calls the CreateFunc. Godot can call us from several threads so we use a
+// gdnative.InstanceRegistry that is safe for concurrent use.
+var Instances = gdnative.NewInstanceRegistry()
 
 // NativeScriptInit will run on NativeScript initialization. It is responsible
 // for registering all our classes with Godot.
@@ -71,7 +72,7 @@ func simpleConstructor(object gdnative.Object, methodData string) string {
 
 	// Use the pointer address as the instance ID
 	instanceID := fmt.Sprintf("%p", instance)
-	Instances[instanceID] = instance
+	Instances.Set(instanceID, instance)
 
 	// Return the instanceID
 	return instanceID
@@ -80,7 +81,7 @@ func simpleConstructor(object gdnative.Object, methodData string) string {
 func simpleDestructor(object gdnative.Object, methodData, userData string) {
 	gdnative.Log.Println("Destroying SimpleClass with ID:", userData, "...")
 	// Delete the instance from our map of instances
-	delete(Instances, userData)
+	Instances.Delete(userData)
 }
 
 func simpleMethod(object gdnative.Object, methodData, userData string, numArgs int, args []gdnative.Variant) gdnative.Variant {
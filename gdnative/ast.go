@@ -22,7 +22,11 @@ import (
 	"go/printer"
 	"go/token"
 	"os"
+	"path"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -95,9 +99,150 @@ func LookupRegistrableTypeDeclarations(pkg *ast.Package) map[string]Registrable
 		}
 	}
 
+	// make a last iteration to resolve pointers to registered classes used
+	// as method params or return values and the inspector type of properties
+	// whose types implement their own Variant conversion
+	codecTypes := lookupCodecTypes(pkg)
+	for _, class := range classes {
+		for _, property := range class.Properties() {
+			if variantType, ok := codecTypes[strings.TrimPrefix(property.gdnativeKind, "*")]; ok {
+				property.kind = fmt.Sprintf("gdnative.%s", variantType)
+			}
+		}
+
+		for _, method := range class.Methods() {
+			for _, param := range method.params {
+				param.class = registeredClassOf(param.kind, classes)
+			}
+
+			for _, value := range method.returnValues {
+				value.class = registeredClassOf(value.kind, classes)
+			}
+		}
+	}
+
 	return classes
 }
 
+// lookupCodecTypes returns back the types of the given package that implement
+// gdnative.VariantMarshaler or encoding.TextMarshaler mapped to the name of the
+// VariantType constant their values are converted into
+func lookupCodecTypes(pkg *ast.Package) map[string]string {
+
+	codecTypes := map[string]string{}
+	for _, file := range pkg.Files {
+		for _, node := range file.Decls {
+			fd, ok := node.(*ast.FuncDecl)
+			if !ok || fd.Recv == nil || fd.Recv.NumFields() != 1 {
+				continue
+			}
+
+			receiver := strings.TrimPrefix(parseDefault(fd.Recv.List[0].Type, ""), "*")
+			switch fd.Name.Name {
+			case "MarshalVariant":
+				// values can be of any type so we use a nil typed property
+				codecTypes[receiver] = "VariantTypeNil"
+			case "MarshalText":
+				if _, ok := codecTypes[receiver]; !ok {
+					codecTypes[receiver] = "VariantTypeString"
+				}
+			}
+		}
+	}
+
+	return codecTypes
+}
+
+// qualifiedIdentifier matches package qualifiers in type names like time.Time
+var qualifiedIdentifier = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\.`)
+
+// LookupImports returns back the import specs (as Go source) of the given package
+// that the generated code needs to declare the method params and properties of
+// the given classes
+func LookupImports(pkg *ast.Package, classes map[string]Registrable) []string {
+
+	qualifiers := map[string]bool{}
+	for _, class := range classes {
+		for _, property := range class.Properties() {
+			if property.SetConvert() != "" {
+				// this property is not declared using its type
+				continue
+			}
+
+			for _, match := range qualifiedIdentifier.FindAllStringSubmatch(property.GoType(), -1) {
+				qualifiers[match[1]] = true
+			}
+		}
+
+		for _, method := range class.Methods() {
+			for i, param := range method.params {
+				if param.class != "" || param.ConvertFunction(fmt.Sprintf("args[%d]", i)) != "" {
+					// this param is not declared using its type
+					continue
+				}
+
+				for _, match := range qualifiedIdentifier.FindAllStringSubmatch(param.GoType(), -1) {
+					qualifiers[match[1]] = true
+				}
+			}
+		}
+	}
+
+	imports := map[string]bool{}
+	for _, file := range pkg.Files {
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+
+			name := path.Base(importPath)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+
+			// the generated code always imports these packages
+			switch name {
+			case "context", "fmt", "gdnative":
+				continue
+			}
+
+			if !qualifiers[name] {
+				continue
+			}
+
+			if spec.Name != nil {
+				imports[fmt.Sprintf("%s %q", name, importPath)] = true
+				continue
+			}
+			imports[strconv.Quote(importPath)] = true
+		}
+	}
+
+	result := []string{}
+	for spec := range imports {
+		result = append(result, spec)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// registeredClassOf returns the registered class name if the given kind is a
+// pointer to it, otherwise it returns an empty string
+func registeredClassOf(kind string, classes map[string]Registrable) string {
+
+	if !strings.HasPrefix(kind, "*") {
+		return ""
+	}
+
+	if _, ok := classes[kind[1:]]; !ok {
+		return ""
+	}
+
+	return kind[1:]
+}
+
 // getClassName extracts and build the right class name for the registry
 func getClassName(tp *ast.TypeSpec) string {
 
@@ -191,8 +336,16 @@ func validateConstructor(structName string, fd *ast.FuncDecl) (*registryConstruc
 		return nil, fmt.Errorf("%s is a method of %s type it can not be used as constructor", funcName, value)
 	}
 
-	if fd.Type.Params.List != nil {
-		return nil, fmt.Errorf("constructors of %s values take no params but %s takes %d", structName, funcName, fd.Type.Params.NumFields())
+	withContext := false
+	switch fd.Type.Params.NumFields() {
+	case 0:
+	case 1:
+		if !isContextType(fd.Type.Params.List[0].Type) {
+			return nil, fmt.Errorf("constructors of %s values can only take a context.Context param but %s takes %s", structName, funcName, parseDefault(fd.Type.Params.List[0].Type, ""))
+		}
+		withContext = true
+	default:
+		return nil, fmt.Errorf("constructors of %s values take no params or a context.Context but %s takes %d", structName, funcName, fd.Type.Params.NumFields())
 	}
 
 	if fd.Type.Results == nil || fd.Type.Results.List == nil {
@@ -216,12 +369,25 @@ func validateConstructor(structName string, fd *ast.FuncDecl) (*registryConstruc
 	}
 
 	constructor := registryConstructor{
-		class:      structName,
-		customFunc: fd.Name.String(),
+		class:       structName,
+		customFunc:  fd.Name.String(),
+		withContext: withContext,
 	}
 	return &constructor, nil
 }
 
+// isContextType returns true if the given expression is the context.Context type
+func isContextType(expr ast.Expr) bool {
+
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	ident, ok := selector.X.(*ast.Ident)
+	return ok && ident.Name == "context" && selector.Sel.Name == "Context"
+}
+
 // lookupInstanceDestroyFunc
 func lookupInstanceDestroyFunc(className string, file *ast.File) *registryDestructor {
 
@@ -287,8 +453,8 @@ func validateDestructor(structName string, fd *ast.FuncDecl) (*registryDestructo
 		return nil, fmt.Errorf("destructors of %s values take no params but %s takes %d", structName, funcName, fd.Type.Params.NumFields())
 	}
 
-	if fd.Type.Results != nil || fd.Type.Results.List != nil || len(fd.Type.Results.List) > 0 {
-		return nil, fmt.Errorf("destructors of %s values have to return a pointer to *%s but %s return nothing", structName, structName, funcName)
+	if fd.Type.Results.NumFields() > 0 {
+		return nil, fmt.Errorf("destructors of %s values must not return anything but %s returns %d values", structName, funcName, fd.Type.Results.NumFields())
 	}
 
 	destructor := registryDestructor{
@@ -329,6 +495,13 @@ func lookupMethods(className string, file *ast.File) []*registryMethod {
 			continue
 		}
 
+		// ignore Objectable and Contextual methods unless they are explicitly
+		// exported, they are used by the generated code to inject the owner
+		// object and the instance context
+		if isInjectionMethod(fd) && !exported {
+			continue
+		}
+
 		// ignore methods from other types
 		switch t := fd.Recv.List[0].Type.(type) {
 		case *ast.StarExpr:
@@ -351,6 +524,7 @@ func lookupMethods(className string, file *ast.File) []*registryMethod {
 			class:        className,
 			params:       lookupParams(fd.Type.Params),
 			returnValues: lookupReturnValues(fd),
+			async:        isAsyncFromDoc(fd.Doc),
 		}
 		methods = append(methods, &method)
 	}
@@ -358,6 +532,58 @@ func lookupMethods(className string, file *ast.File) []*registryMethod {
 	return methods
 }
 
+// injectionMethods are the signatures of the Objectable and Contextual
+// methods (and the InstanceContext accessor) by method name
+var injectionMethods = map[string]string{
+	"BaseClass":  "() string",
+	"Owner":      "() gdnative.Object",
+	"SetOwner":   "(gdnative.Object)",
+	"Context":    "() context.Context",
+	"SetContext": "(context.Context)",
+}
+
+// isInjectionMethod returns true if the given method is part of the
+// Objectable or Contextual interfaces (or the InstanceContext accessor),
+// methods with the same name but a different signature are regular methods
+func isInjectionMethod(fd *ast.FuncDecl) bool {
+
+	signature, ok := injectionMethods[fd.Name.String()]
+	if !ok {
+		return false
+	}
+
+	return funcSignature(fd.Type) == signature
+}
+
+// funcSignature returns back the parameter and result types of the given
+// function type as "(param, param) result, result"
+func funcSignature(ft *ast.FuncType) string {
+
+	kinds := func(fields *ast.FieldList) []string {
+		result := []string{}
+		if fields == nil {
+			return result
+		}
+
+		for _, field := range fields.List {
+			kind := parseDefault(field.Type, "")
+			result = append(result, kind)
+			for i := 1; i < len(field.Names); i++ {
+				result = append(result, kind)
+			}
+		}
+
+		return result
+	}
+
+	signature := fmt.Sprintf("(%s)", strings.Join(kinds(ft.Params), ", "))
+	if results := kinds(ft.Results); len(results) > 0 {
+		signature = fmt.Sprintf("%s %s", signature, strings.Join(results, ", "))
+	}
+
+	return signature
+}
+
 // lookupSignals look up for every signal that is owned by the type and fill
 // a registration data structure with it
 func lookupSignals(className string, file ast.Node) []*registrySignal {
@@ -509,14 +735,7 @@ func lookupProperties(className string, file *ast.File) []*registryProperty {
 						continue
 					default:
 						gdnativeKind = kind
-						typeFormat := fmt.Sprintf("VariantType%s", strings.ReplaceAll(kind, "gdnative.", ""))
-						_, ok := VariantTypeLookupMap[typeFormat]
-						if ok {
-							kind = fmt.Sprintf("gdnative.%s", typeFormat)
-							break
-						}
-
-						kind = "gdnative.VariantTypeObject"
+						kind = fmt.Sprintf("gdnative.%s", propertyVariantType(kind))
 					}
 
 					alias, exported := extractExportedAndAliasFromDoc(field.Doc)
@@ -576,6 +795,44 @@ func lookupProperties(className string, file *ast.File) []*registryProperty {
 	return properties
 }
 
+// propertyVariantType returns the name of the VariantType constant that Godot
+// uses in the inspector for a property of the given kind
+func propertyVariantType(kind string) string {
+
+	switch kind {
+	case "bool":
+		return "VariantTypeBool"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte",
+		"gdnative.Int64T", "gdnative.Uint64T", "gdnative.Uint", "gdnative.Uint8T", "gdnative.Uint32T":
+		return "VariantTypeInt"
+	case "float32", "float64", "gdnative.Float", "gdnative.Double":
+		return "VariantTypeReal"
+	case "string", "time.Time":
+		return "VariantTypeString"
+	case "time.Duration":
+		return "VariantTypeReal"
+	case "gdnative.Variant":
+		// Godot accepts values of any type in nil typed properties
+		return "VariantTypeNil"
+	case "ArrayType[byte]", "ArrayType[uint8]":
+		return "VariantTypePoolByteArray"
+	}
+
+	switch {
+	case strings.HasPrefix(kind, "ArrayType["), strings.HasPrefix(kind, "gdnative.TypedArray["):
+		return "VariantTypeArray"
+	case strings.HasPrefix(kind, "MapType["), strings.HasPrefix(kind, "gdnative.TypedDictionary["):
+		return "VariantTypeDictionary"
+	}
+
+	typeFormat := fmt.Sprintf("VariantType%s", strings.ReplaceAll(kind, "gdnative.", ""))
+	if _, ok := VariantTypeLookupMap[typeFormat]; ok {
+		return typeFormat
+	}
+
+	return "VariantTypeObject"
+}
+
 func mustSetPropertyTagRset(property *registryProperty, value string) {
 
 	rpcMode := fmt.Sprintf("MethodRpcMode%s", strings.Title(value))
@@ -645,6 +902,25 @@ func extractExportedAndAliasFromDoc(doc *ast.CommentGroup) (string, bool) {
 	return alias, exported
 }
 
+// isAsyncFromDoc returns true if the given doc exports the method as async
+// using the godot::export async annotation
+func isAsyncFromDoc(doc *ast.CommentGroup) bool {
+
+	if doc == nil {
+		return false
+	}
+
+	for _, line := range doc.List {
+		docstring := strings.TrimSpace(strings.ReplaceAll(line.Text, "/", ""))
+		if strings.HasPrefix(docstring, godotExport) {
+			fields := strings.Fields(strings.TrimPrefix(docstring, godotExport))
+			return len(fields) > 0 && fields[0] == "async"
+		}
+	}
+
+	return false
+}
+
 func parseDefault(expr ast.Expr, def string) string {
 
 	kind := def
@@ -659,8 +935,16 @@ func parseDefault(expr ast.Expr, def string) string {
 		kind = parseArray(t)
 	case *ast.MapType:
 		kind = parseMap(t)
+	case *ast.IndexExpr:
+		kind = parseIndexExpr(t)
+	case *ast.IndexListExpr:
+		kind = parseIndexListExpr(t)
 	case *ast.SelectorExpr:
 		kind = fmt.Sprintf("%s.%s", parseDefault(t.X, def), t.Sel.String())
+	case *ast.InterfaceType:
+		if t.Methods.NumFields() == 0 {
+			kind = "interface{}"
+		}
 	}
 
 	return kind
@@ -692,6 +976,25 @@ func parseMap(field *ast.MapType) string {
 	return fmt.Sprintf(result, key, value)
 }
 
+// parseIndexExpr parses the instantiation of a generic type with a single
+// type argument like gdnative.TypedArray[int64]
+func parseIndexExpr(expr *ast.IndexExpr) string {
+
+	return fmt.Sprintf("%s[%s]", parseDefault(expr.X, "gdnative.Pointer"), parseDefault(expr.Index, "gdnative.Pointer"))
+}
+
+// parseIndexListExpr parses the instantiation of a generic type with many
+// type arguments like gdnative.TypedDictionary[string, int64]
+func parseIndexListExpr(expr *ast.IndexListExpr) string {
+
+	indices := make([]string, 0, len(expr.Indices))
+	for _, index := range expr.Indices {
+		indices = append(indices, parseDefault(index, "gdnative.Pointer"))
+	}
+
+	return fmt.Sprintf("%s[%s]", parseDefault(expr.X, "gdnative.Pointer"), strings.Join(indices, ", "))
+}
+
 func parseKeyValueExpr(expr *ast.KeyValueExpr) (string, string) { //nolint:unused
 
 	var value string
@@ -0,0 +1,82 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// injectionSource declares a class implementing Objectable and Contextual
+// together with a class whose methods only share their names
+const injectionSource = `package player
+
+import (
+	"context"
+
+	"gitlab.com/pimpam-games-studio/gdnative-go/gdnative"
+)
+
+type Player struct{}
+
+func (p *Player) BaseClass() string { return "Node" }
+func (p *Player) Owner() gdnative.Object { return gdnative.Object{} }
+func (p *Player) SetOwner(owner gdnative.Object) {}
+func (p *Player) Context() context.Context { return nil }
+func (p *Player) SetContext(ctx context.Context) {}
+func (p *Player) Heal(amount int) {}
+
+type Account struct{}
+
+func (a *Account) Owner() string { return "" }
+func (a *Account) SetOwner(name string) {}
+func (a *Account) Context(key string) string { return "" }
+func (a *Account) SetContext(key, value string) {}
+func (a *Account) BaseClass(name string) string { return name }
+`
+
+func TestInjectionMethods(t *testing.T) {
+
+	file, err := parser.ParseFile(token.NewFileSet(), "player.go", injectionSource, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("could not parse the source: %v", err)
+	}
+
+	tests := []struct {
+		class string
+		want  []string
+	}{
+		{"Player", []string{"Heal"}},
+		{"Account", []string{"BaseClass", "Context", "Owner", "SetContext", "SetOwner"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.class, func(t *testing.T) {
+			names := []string{}
+			for _, method := range lookupMethods(test.class, file) {
+				names = append(names, method.GetName())
+			}
+			sort.Strings(names)
+
+			if !reflect.DeepEqual(names, test.want) {
+				t.Errorf("registered methods = %v; want %v", names, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+/*
+#include <gdnative/variant.h>
+#include "gdnative.gen.h"
+#include "util.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime/debug"
+	"unsafe"
+)
+
+const (
+	// AsyncCompletedSignal is the signal emitted by async jobs when the Go
+	// function finishes, its only argument is the function result
+	AsyncCompletedSignal = "completed"
+
+	// AsyncFailedSignal is the signal emitted by async jobs when the Go
+	// function returns an error, its only argument is the error message
+	AsyncFailedSignal = "failed"
+)
+
+// RunAsync runs the given function in a new goroutine and returns right away
+// a job object (a Godot Reference) to the caller. When the function returns
+// the job emits AsyncCompletedSignal with the function result or
+// AsyncFailedSignal with the error message so GDScript can wait for it:
+//
+//	var job = go_node.long_computation(42)
+//	var result = yield(job, "completed")
+//
+// Signals are emitted on Godot's main thread through RunOnMain that adds the
+// MainThreadDispatcherClass node to the scene tree if needed, a warning is
+// logged if it can not do it. The given function runs after the calling
+// method returned so it must not use Variants borrowed from Godot, generated
+// code copies them and passes the copies as arguments. RunAsync owns the given
+// arguments and destroys them once the function returns, unless the function
+// returns one of them as its result. This is used by methods exported with
+// godot::export async.
+func RunAsync(fn func() (Variant, error), arguments ...Variant) Variant {
+	GDNative.checkInit()
+
+	job, err := newAsyncJob()
+	if err != nil {
+		destroyAsyncArguments(arguments, Variant{})
+		Log.Error(fmt.Sprintf("could not create async job: %s", err))
+		return NewVariantNil()
+	}
+
+	// keep our own reference to the job until its signals are emitted so
+	// Godot does not free it if GDScript drops the job before it finishes,
+	// it must be taken before anything else references the job
+	keep := newVariantWithObject(job)
+	if err := addAsyncSignals(job); err != nil {
+		keep.Destroy()
+		destroyAsyncArguments(arguments, Variant{})
+		Log.Error(fmt.Sprintf("could not create async job: %s", err))
+		return NewVariantNil()
+	}
+
+	go func() {
+		result, err := runAsyncFunc(fn)
+		destroyAsyncArguments(arguments, result)
+		RunOnMain(func() {
+			defer keep.Destroy()
+
+			if err != nil {
+				message := NewVariantWithString(String(err.Error()))
+				defer message.Destroy()
+				EmitSignal(job, AsyncFailedSignal, message)
+				return
+			}
+
+			defer result.Destroy()
+			EmitSignal(job, AsyncCompletedSignal, result)
+		})
+	}()
+
+	// return a copy of our reference, Godot takes ownership of it
+	var ret C.godot_variant
+	C.go_godot_variant_new_copy(GDNative.api, &ret, keep.getBase())
+
+	return newOwnedVariant(&ret)
+}
+
+// destroyAsyncArguments destroys the arguments owned by an async job but the
+// one moved into its result
+func destroyAsyncArguments(arguments []Variant, result Variant) {
+	for _, argument := range arguments {
+		if argument.base != nil && argument.base == result.base {
+			continue
+		}
+		argument.Destroy()
+	}
+}
+
+// runAsyncFunc executes the given async function recovering from any panic,
+// panics are reported back as errors so the job always emits a signal
+func runAsyncFunc(fn func() (Variant, error)) (result Variant, err error) {
+	defer func() {
+		if strict.Load() {
+			return
+		}
+		if r := recover(); r != nil {
+			Log.Error(fmt.Sprintf("recovered from panic in async function: %v\n%s", r, debug.Stack()))
+			result, err = NewVariantNil(), fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return fn()
+}
+
+// newAsyncJob creates a new Reference to be used as async job
+func newAsyncJob() (Object, error) {
+
+	className := C.CString("Reference")
+	defer C.free(unsafe.Pointer(className))
+
+	constructor := C.go_godot_get_class_constructor(GDNative.api, className)
+	job := Object{base: C.cgo_construct_object(constructor)}
+	if job.getBase() == nil {
+		return job, fmt.Errorf("Reference class constructor returned nil")
+	}
+
+	return job, nil
+}
+
+// addAsyncSignals adds the completed and failed user signals to the given job
+func addAsyncSignals(job Object) error {
+
+	for _, signal := range []string{AsyncCompletedSignal, AsyncFailedSignal} {
+		name := NewVariantWithString(String(signal))
+		result, err := job.Call("add_user_signal", name)
+		name.Destroy()
+		result.Destroy()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newVariantWithObject creates a new Variant holding the given object, if the
+// object is a Reference the Variant holds a reference to it
+func newVariantWithObject(object Object) Variant {
+	var variant C.godot_variant
+	C.go_godot_variant_new_object(GDNative.api, &variant, object.getBase())
+
+	return newOwnedVariant(&variant)
+}
@@ -0,0 +1,177 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+/*
+#include <gdnative/variant.h>
+#include "gdnative.gen.h"
+#include "variant.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// Errors returned (wrapped in a *CallError) by Object.Call and MethodBind.Call,
+// use errors.Is to check which kind of error a call returned
+var (
+	ErrInvalidMethod    = errors.New("invalid method")
+	ErrInvalidArgument  = errors.New("invalid argument")
+	ErrTooManyArguments = errors.New("too many arguments")
+	ErrTooFewArguments  = errors.New("too few arguments")
+	ErrInstanceIsNull   = errors.New("instance is null")
+	ErrUnknownCallError = errors.New("unknown call error")
+)
+
+// CallError is returned when Godot could not call a method, it decodes the
+// godot_variant_call_error structure that Godot fills on every variant call
+type CallError struct {
+	// Method is the name of the method that was called
+	Method string
+	// Err is one of the Err* errors defined in this package
+	Err error
+	// Argument is the index of the invalid argument when Err is
+	// ErrInvalidArgument or the expected number of arguments when Err is
+	// ErrTooManyArguments or ErrTooFewArguments
+	Argument int
+	// Expected is the expected type of the invalid argument when Err is
+	// ErrInvalidArgument
+	Expected VariantType
+}
+
+// Error implements the error interface
+func (e *CallError) Error() string {
+
+	switch e.Err {
+	case ErrInvalidArgument:
+		return fmt.Sprintf("calling %s: invalid argument %d, expected %s", e.Method, e.Argument, variantTypeName(e.Expected))
+	case ErrTooManyArguments, ErrTooFewArguments:
+		return fmt.Sprintf("calling %s: %s, expected %d", e.Method, e.Err, e.Argument)
+	}
+
+	return fmt.Sprintf("calling %s: %s", e.Method, e.Err)
+}
+
+// Unwrap returns back the underlying Err* error
+func (e *CallError) Unwrap() error {
+	return e.Err
+}
+
+// newCallError decodes the given godot_variant_call_error into a Go error,
+// it returns nil if the call was successful
+func newCallError(method string, callError C.godot_variant_call_error) error {
+
+	var err error
+	switch callError.error {
+	case C.GODOT_CALL_ERROR_CALL_OK:
+		return nil
+	case C.GODOT_CALL_ERROR_CALL_ERROR_INVALID_METHOD:
+		err = ErrInvalidMethod
+	case C.GODOT_CALL_ERROR_CALL_ERROR_INVALID_ARGUMENT:
+		err = ErrInvalidArgument
+	case C.GODOT_CALL_ERROR_CALL_ERROR_TOO_MANY_ARGUMENTS:
+		err = ErrTooManyArguments
+	case C.GODOT_CALL_ERROR_CALL_ERROR_TOO_FEW_ARGUMENTS:
+		err = ErrTooFewArguments
+	case C.GODOT_CALL_ERROR_CALL_ERROR_INSTANCE_IS_NULL:
+		err = ErrInstanceIsNull
+	default:
+		err = ErrUnknownCallError
+	}
+
+	return &CallError{
+		Method:   method,
+		Err:      err,
+		Argument: int(callError.argument),
+		Expected: VariantType(callError.expected),
+	}
+}
+
+// variantTypeName returns back a human readable name for the given VariantType
+func variantTypeName(variantType VariantType) string {
+
+	for name, value := range VariantTypeLookupMap {
+		if value == variantType {
+			return strings.TrimPrefix(name, "VariantType")
+		}
+	}
+
+	return fmt.Sprintf("VariantType(%d)", int(variantType))
+}
+
+// Call calls the method with the given name on this Godot object using
+// Godot's dynamic dispatch, this means that it can call engine methods as well
+// as methods defined in GDScript or any other script language. The returned
+// Variant is owned by the caller. Like any other Godot API it must be called
+// from Godot's main thread.
+func (gdt Object) Call(method string, args ...Variant) (Variant, error) {
+	GDNative.checkInit()
+	if gdt.getBase() == nil {
+		return NewVariantNil(), &CallError{Method: method, Err: ErrInstanceIsNull}
+	}
+
+	var self C.godot_variant
+	C.go_godot_variant_new_object(GDNative.api, &self, gdt.getBase())
+	defer C.go_godot_variant_destroy(GDNative.api, &self)
+
+	return callVariant(&self, method, args)
+}
+
+// callVariant calls the method with the given name on the given variant
+// using Godot's dynamic dispatch, the returned Variant is owned by the caller
+func callVariant(self *C.godot_variant, method string, args []Variant) (Variant, error) {
+
+	name := String(method).getBase()
+	defer C.go_godot_string_destroy(GDNative.api, name)
+
+	cArgs, numArgs := buildVariantArgs(args)
+	defer C.free(unsafe.Pointer(cArgs))
+
+	var callError C.godot_variant_call_error
+	result := C.go_godot_variant_call(GDNative.api, self, name, cArgs, C.godot_int(numArgs), &callError)
+
+	return newOwnedVariant(&result), newCallError(method, callError)
+}
+
+// Call calls this method bind on the given Godot object with the given
+// arguments, contrary to MethodBindPtrCall it does type checking, supports
+// vararg methods and reports errors back. The returned Variant is owned by
+// the caller.
+func (gdt MethodBind) Call(instance Object, args ...Variant) (Variant, error) {
+	GDNative.checkInit()
+	if instance.getBase() == nil {
+		return NewVariantNil(), &CallError{Method: "method bind", Err: ErrInstanceIsNull}
+	}
+
+	cArgs, numArgs := buildVariantArgs(args)
+	defer C.free(unsafe.Pointer(cArgs))
+
+	var callError C.godot_variant_call_error
+	result := C.go_godot_method_bind_call(GDNative.api, gdt.getBase(), instance.getBase(), cArgs, C.int(numArgs), &callError)
+
+	return newOwnedVariant(&result), newCallError("method bind", callError)
+}
+
+// buildVariantArgs builds a C array of pointers to the given variants, the
+// returned array must be freed by the caller
+func buildVariantArgs(args []Variant) (**C.godot_variant, int) {
+	variants := VariantArray{array: args}
+	return variants.getBase(), len(args)
+}
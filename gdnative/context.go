@@ -0,0 +1,60 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import "context"
+
+// Contextual is implemented by registered types that want to receive the
+// context.Context of their instance. Every instance created by Godot gets its
+// own context that is canceled when Godot destroys the instance (right before
+// its godot::destructor is called) so goroutines started by the instance can
+// stop before they touch stale state.
+type Contextual interface {
+	SetContext(context.Context)
+}
+
+// InstanceContext implements Contextual, it can be embedded into any
+// registered type to have its instance context injected when it is created:
+//
+//	type Downloader struct {
+//		gdnative.InstanceContext
+//	}
+//
+//	func (d *Downloader) Start(url string) {
+//		go func() {
+//			req, _ := http.NewRequestWithContext(d.Context(), "GET", url, nil)
+//			...
+//		}()
+//	}
+type InstanceContext struct {
+	ctx context.Context
+}
+
+// SetContext sets the instance context
+func (c *InstanceContext) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// Context returns back the instance context, if no context has been set yet
+// it returns back an empty context that is never canceled
+func (c *InstanceContext) Context() context.Context {
+
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
@@ -0,0 +1,443 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CrashDirEnv is the environment variable that overrides the directory where
+// crash files are written
+const CrashDirEnv = "GDNATIVE_GO_CRASH_DIR"
+
+// recentCallsSize is the number of boundary calls kept for crash reports
+const recentCallsSize = 64
+
+// recentCallLineSize is the maximum length of a boundary call line in crash
+// reports, longer lines are truncated so the calls fit in the space reserved
+// for them in the session file
+const recentCallLineSize = 160
+
+// recentCallsFlushInterval is how often the recent boundary calls are
+// written into the session file
+const recentCallsFlushInterval = 100 * time.Millisecond
+
+// recentCallsTitle is the title of the recent boundary calls section
+const recentCallsTitle = "\n== recent boundary calls (oldest first) ==\n"
+
+// recentCallsSectionSize is the size of the space reserved for the recent
+// boundary calls in the session file
+const recentCallsSectionSize = len(recentCallsTitle) + recentCallsSize*recentCallLineSize + 1
+
+// crashTimeFormat is the time format used in crash file names
+const crashTimeFormat = "20060102-150405"
+
+// crashReporter holds the crash reporter state
+var crashReporter = struct {
+	mu       sync.Mutex
+	disabled bool
+	dir      string
+	session  *os.File
+	stop     chan struct{}
+	flushed  chan struct{}
+}{}
+
+// SetCrashDir sets the directory where crash files are written, it should be
+// called from an init function so it is set before Godot initializes the
+// library. The CrashDirEnv environment variable takes precedence over it. By
+// default crash files are written into gdnative-go/crashes under the user
+// cache directory.
+func SetCrashDir(dir string) {
+	crashReporter.mu.Lock()
+	crashReporter.dir = dir
+	crashReporter.mu.Unlock()
+}
+
+// DisableCrashReports disables the crash reporter, it must be called from an
+// init function to have any effect
+func DisableCrashReports() {
+	crashReporter.mu.Lock()
+	crashReporter.disabled = true
+	crashReporter.mu.Unlock()
+}
+
+// crashDir returns back the directory where crash files are written
+func crashDir() string {
+
+	if dir := os.Getenv(CrashDirEnv); dir != "" {
+		return dir
+	}
+
+	if crashReporter.dir != "" {
+		return crashReporter.dir
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+
+	return filepath.Join(cacheDir, "gdnative-go", "crashes")
+}
+
+// installCrashReporter is called when GDNative is initialized. Fatal errors
+// of the Go runtime (unrecovered panics in goroutines, concurrent map writes,
+// cgo pointer violations...) kill the process before any Go code can run so
+// the runtime is told to write its crash output, with every goroutine stack,
+// into a session file that already contains the build information. The last
+// boundary calls are rewritten into a section reserved for them in the session
+// file every recentCallsFlushInterval, so the calls made right before a fatal
+// error may be missing from it. When the library is terminated cleanly the
+// session file is removed, a session file left behind by a previous run means
+// that it crashed and its path is printed through Godot's error output.
+func installCrashReporter() {
+	crashReporter.mu.Lock()
+	defer crashReporter.mu.Unlock()
+
+	if crashReporter.disabled || crashReporter.session != nil {
+		return
+	}
+
+	// boundary calls are not recorded at all when crash reports are disabled
+	recentCalls.enabled.Store(true)
+
+	dir := crashDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		Log.Warning(fmt.Sprintf("could not create crash directory %s: %s", dir, err))
+		return
+	}
+
+	reportPreviousCrashes(dir)
+
+	name := fmt.Sprintf("session-%s-%d.log", time.Now().Format(crashTimeFormat), os.Getpid())
+	session, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		Log.Warning(fmt.Sprintf("could not create crash session file: %s", err))
+		return
+	}
+
+	writeCrashHeader(session, "fatal error")
+
+	// the runtime appends its crash output at the current file offset so the
+	// recent calls are rewritten in place without moving it
+	offset, err := session.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = session.Write(recentCallsSection())
+	}
+	if err != nil {
+		Log.Warning(fmt.Sprintf("could not write crash session file: %s", err))
+		session.Close()
+		os.Remove(session.Name())
+		return
+	}
+
+	if err := debug.SetCrashOutput(session, debug.CrashOptions{}); err != nil {
+		Log.Warning(fmt.Sprintf("could not set crash output: %s", err))
+		session.Close()
+		os.Remove(session.Name())
+		return
+	}
+
+	// print every goroutine stack on fatal errors, not only the failing one
+	debug.SetTraceback("all")
+	crashReporter.session = session
+	crashReporter.stop = make(chan struct{})
+	crashReporter.flushed = make(chan struct{})
+	go flushRecentCalls(session, offset, crashReporter.stop, crashReporter.flushed)
+}
+
+// uninstallCrashReporter is called when the library is terminated cleanly,
+// it removes the session file as there was no crash
+func uninstallCrashReporter() {
+	crashReporter.mu.Lock()
+	defer crashReporter.mu.Unlock()
+
+	recentCalls.enabled.Store(false)
+	if crashReporter.session == nil {
+		return
+	}
+
+	close(crashReporter.stop)
+	<-crashReporter.flushed
+	crashReporter.stop, crashReporter.flushed = nil, nil
+
+	debug.SetCrashOutput(nil, debug.CrashOptions{})
+	crashReporter.session.Close()
+	os.Remove(crashReporter.session.Name())
+	crashReporter.session = nil
+}
+
+// updateCrashSession appends the registered classes to the session file, it
+// is called once NativeScript has registered every class
+func updateCrashSession() {
+	crashReporter.mu.Lock()
+	defer crashReporter.mu.Unlock()
+
+	if crashReporter.session != nil {
+		writeRegisteredClasses(crashReporter.session)
+	}
+}
+
+// reportPreviousCrashes looks for session files left behind by previous runs
+// that contain Go runtime crash output, renames them as crash files and
+// prints their path through Godot's error output
+func reportPreviousCrashes(dir string) {
+
+	sessions, err := filepath.Glob(filepath.Join(dir, "session-*.log"))
+	if err != nil {
+		return
+	}
+
+	for _, session := range sessions {
+		data, err := os.ReadFile(session)
+		if err != nil {
+			continue
+		}
+
+		// the process was killed without crashing (or it is still running)
+		if !bytes.Contains(data, []byte("\ngoroutine ")) {
+			continue
+		}
+
+		crash := filepath.Join(dir, "crash-"+strings.TrimPrefix(filepath.Base(session), "session-"))
+		if err := os.Rename(session, crash); err != nil {
+			continue
+		}
+
+		Log.Error(fmt.Sprintf("the Go library crashed in a previous run, crash report written to %s", crash))
+	}
+}
+
+// WriteCrashReport writes a crash report with the given reason, every
+// goroutine stack, the build information, the registered classes and the
+// last boundary calls made by Godot into the crash directory. It returns back
+// the report path, that is also printed through Godot's error output. The
+// boundary calls are only recorded while crash reports are enabled.
+func WriteCrashReport(reason string) (string, error) {
+
+	crashReporter.mu.Lock()
+	dir := crashDir()
+	crashReporter.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("report-%s-%d.log", time.Now().Format(crashTimeFormat), os.Getpid())
+	path := filepath.Join(dir, name)
+	report, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer report.Close()
+
+	writeCrashHeader(report, reason)
+	writeRegisteredClasses(report)
+	writeRecentCalls(report)
+
+	fmt.Fprintf(report, "\n== goroutines ==\n%s\n", allStacks())
+
+	Log.Error(fmt.Sprintf("%s, crash report written to %s", reason, path))
+	return path, nil
+}
+
+// writeCrashHeader writes the crash reason, process and build information
+func writeCrashHeader(w io.Writer, reason string) {
+
+	fmt.Fprintf(w, "== gdnative-go crash report ==\n")
+	fmt.Fprintf(w, "reason: %s\n", reason)
+	fmt.Fprintf(w, "time: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(w, "pid: %d\n", os.Getpid())
+	fmt.Fprintf(w, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(w, "\n== build info ==\n%s\n", info)
+	}
+}
+
+// writeRegisteredClasses writes the classes registered with Godot
+func writeRegisteredClasses(w io.Writer) {
+	fmt.Fprintf(w, "\n== registered classes ==\n%s\n", strings.Join(registeredClasses.list(), "\n"))
+}
+
+// writeRecentCalls writes the last calls that Godot made into Go
+func writeRecentCalls(w io.Writer) {
+
+	io.WriteString(w, recentCallsTitle)
+	for _, call := range recentCalls.list() {
+		io.WriteString(w, call.line())
+	}
+}
+
+// recentCallsSection returns back the recent boundary calls padded to the
+// size reserved for them in the session file
+func recentCallsSection() []byte {
+
+	var section bytes.Buffer
+	writeRecentCalls(&section)
+	section.WriteString(strings.Repeat(" ", recentCallsSectionSize-section.Len()-1))
+	section.WriteByte('\n')
+
+	return section.Bytes()
+}
+
+// flushRecentCalls rewrites the recent boundary calls section of the session
+// file at the given offset whenever new calls are recorded until stop is
+// closed
+func flushRecentCalls(session *os.File, offset int64, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(recentCallsFlushInterval)
+	defer ticker.Stop()
+
+	var flushed uint64
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if recorded := recentCalls.recorded(); recorded != flushed {
+			session.WriteAt(recentCallsSection(), offset)
+			flushed = recorded
+		}
+	}
+}
+
+// allStacks returns back the stack of every goroutine
+func allStacks() []byte {
+
+	buffer := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buffer, true)
+		if n < len(buffer) {
+			return buffer[:n]
+		}
+		buffer = make([]byte, len(buffer)*2)
+	}
+}
+
+// classList is a concurrent safe list of registered class names
+type classList struct {
+	mu      sync.Mutex
+	classes map[string]string
+}
+
+// registeredClasses keeps the classes registered with Godot and their base
+var registeredClasses = &classList{classes: map[string]string{}}
+
+// add records the given class
+func (c *classList) add(name, base string) {
+	c.mu.Lock()
+	c.classes[name] = base
+	c.mu.Unlock()
+}
+
+// clear removes every class from the list
+func (c *classList) clear() {
+	c.mu.Lock()
+	c.classes = map[string]string{}
+	c.mu.Unlock()
+}
+
+// list returns back the sorted list of classes as "Class (Base)"
+func (c *classList) list() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	classes := make([]string, 0, len(c.classes))
+	for name, base := range c.classes {
+		classes = append(classes, fmt.Sprintf("%s (%s)", name, base))
+	}
+	sort.Strings(classes)
+
+	return classes
+}
+
+// boundaryCall is a call made by Godot into Go
+type boundaryCall struct {
+	sequence   uint64
+	time       time.Time
+	kind       string
+	methodData string
+}
+
+// line returns back the call formatted as a crash report line
+func (c *boundaryCall) line() string {
+
+	line := fmt.Sprintf("%s %s %s", c.time.Format("15:04:05.000000"), c.kind, c.methodData)
+	if len(line) >= recentCallLineSize {
+		line = line[:recentCallLineSize-1]
+	}
+
+	return line + "\n"
+}
+
+// callRing is a lock free ring buffer of the last boundary calls, every call
+// claims the next slot with an atomic counter so recording never blocks the
+// thread Godot calls us from
+type callRing struct {
+	enabled atomic.Bool
+	next    atomic.Uint64
+	calls   [recentCallsSize]atomic.Pointer[boundaryCall]
+}
+
+// recentCalls keeps the last boundary calls for crash reports
+var recentCalls = new(callRing)
+
+// record adds a call to the ring buffer if crash reports are enabled
+func (r *callRing) record(kind, methodData string) {
+
+	if !r.enabled.Load() {
+		return
+	}
+
+	sequence := r.next.Add(1)
+	r.calls[sequence%recentCallsSize].Store(&boundaryCall{
+		sequence: sequence, time: time.Now(), kind: kind, methodData: methodData,
+	})
+}
+
+// recorded returns back the number of calls recorded so far
+func (r *callRing) recorded() uint64 {
+	return r.next.Load()
+}
+
+// list returns back the recorded calls from the oldest to the newest
+func (r *callRing) list() []boundaryCall {
+
+	calls := make([]boundaryCall, 0, recentCallsSize)
+	for i := range r.calls {
+		if call := r.calls[i].Load(); call != nil {
+			calls = append(calls, *call)
+		}
+	}
+
+	sort.Slice(calls, func(i, j int) bool { return calls[i].sequence < calls[j].sequence })
+	return calls
+}
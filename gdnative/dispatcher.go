@@ -0,0 +1,321 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+/*
+#include "gdnative.gen.h"
+#include "util.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// MainThreadDispatcherClass is the name of the Node class that gdnative-go
+// registers with Godot to drain the RunOnMain queue. Godot only processes
+// nodes that are part of the scene tree, the first time a function is queued
+// a node of this class is added to the root of the SceneTree automatically.
+// It can also be added as an AutoLoad (or to any scene that is always loaded)
+// so the queue is drained from the very first frame:
+//
+//	[gd_resource type="NativeScript" load_steps=2 format=2]
+//	[ext_resource path="res://bin/library.gdnlib" type="GDNativeLibrary" id=1]
+//	[resource]
+//	class_name = "GoMainThreadDispatcher"
+//	library = ExtResource( 1 )
+const MainThreadDispatcherClass = "GoMainThreadDispatcher"
+
+// mainThreadID holds the identifier of Godot's main thread, it is set when
+// NativeScript is initialized and every time the dispatcher node is processed
+var mainThreadID uint64
+
+// mainQueue holds the functions waiting to be executed on Godot's main thread
+var mainQueue = new(dispatchQueue)
+
+// dispatcher states, the dispatcher is attached once a node of the
+// MainThreadDispatcherClass processes the queue or once we add one ourselves
+const (
+	dispatcherDetached int32 = iota
+	dispatcherAttaching
+	dispatcherAttached
+)
+
+var (
+	dispatcherState   int32
+	dispatcherWarning sync.Once
+)
+
+// dispatchQueue is a concurrent safe FIFO queue of functions
+type dispatchQueue struct {
+	mu    sync.Mutex
+	funcs []func()
+}
+
+// push adds the given function to the end of the queue
+func (q *dispatchQueue) push(fn func()) {
+	q.mu.Lock()
+	q.funcs = append(q.funcs, fn)
+	q.mu.Unlock()
+}
+
+// take empties the queue and returns back the functions that were on it
+func (q *dispatchQueue) take() []func() {
+	q.mu.Lock()
+	funcs := q.funcs
+	q.funcs = nil
+	q.mu.Unlock()
+
+	return funcs
+}
+
+// RunOnMain queues the given function to be executed on Godot's main thread.
+// Most Godot APIs must not be called from arbitrary goroutines, goroutines
+// that need to emit signals or touch the scene tree should do it through
+// this function. Queued functions are executed in order by the node
+// registered as MainThreadDispatcherClass on its next _process call, the node
+// is added to the SceneTree when needed.
+func RunOnMain(fn func()) {
+	if fn == nil {
+		return
+	}
+
+	mainQueue.push(fn)
+	ensureMainThreadDispatcher()
+}
+
+// RunOnMainSync executes the given function on Godot's main thread and waits
+// for it to return its result. If it is called from the main thread itself
+// the function is executed right away. The calling goroutine blocks until the
+// dispatcher node processes the queue so it should never be used from code
+// that the main thread waits on. If the library is terminated while waiting
+// the function is never executed and nil is returned.
+func RunOnMainSync(fn func() interface{}) interface{} {
+	if IsMainThread() {
+		return fn()
+	}
+
+	result := make(chan interface{}, 1)
+	RunOnMain(func() {
+		var value interface{}
+		defer func() {
+			result <- value
+		}()
+
+		value = fn()
+	})
+
+	select {
+	case value := <-result:
+		return value
+	case <-Context().Done():
+		return nil
+	}
+}
+
+// IsMainThread returns true if the caller is running on Godot's main thread
+func IsMainThread() bool {
+	id := atomic.LoadUint64(&mainThreadID)
+	return id != 0 && id == uint64(C.cgo_current_thread_id())
+}
+
+// ProcessMainQueue executes every function queued with RunOnMain, functions
+// queued while the queue is being processed will run in the next call. It is
+// called on every frame by the MainThreadDispatcherClass node but it can also
+// be called from any other method that Godot calls on the main thread.
+func ProcessMainQueue() {
+	for _, fn := range mainQueue.take() {
+		runQueued(fn)
+	}
+}
+
+// runQueued executes a single queued function recovering from any panic
+func runQueued(fn func()) {
+	defer func() {
+		if strict.Load() {
+			return
+		}
+		if r := recover(); r != nil {
+			Log.Error(fmt.Sprintf("recovered from panic in function queued with RunOnMain: %v\n%s", r, debug.Stack()))
+		}
+	}()
+
+	fn()
+}
+
+// setMainThread marks the caller thread as Godot's main thread
+func setMainThread() {
+	atomic.StoreUint64(&mainThreadID, uint64(C.cgo_current_thread_id()))
+}
+
+// registerMainThreadDispatcher registers the MainThreadDispatcherClass node
+// with Godot, its _process method drains the RunOnMain queue on every frame
+func registerMainThreadDispatcher() {
+
+	process := func(object Object, methodData, userData string, numArgs int, args []Variant) Variant {
+		setMainThread()
+		atomic.StoreInt32(&dispatcherState, dispatcherAttached)
+		ProcessMainQueue()
+		return NewVariantNil()
+	}
+
+	methods := []Method{NewGodotMethod(MainThreadDispatcherClass, "_process", process)}
+	RegisterNewGodotClass(false, MainThreadDispatcherClass, "Node", nil, nil, methods, nil, nil)
+}
+
+// ensureMainThreadDispatcher adds a MainThreadDispatcherClass node to the
+// SceneTree if no node of that class is draining the queue yet, if it can not
+// be added a warning is logged once as queued functions would never run
+func ensureMainThreadDispatcher() {
+
+	if !GDNative.IsInitialized() || atomic.LoadInt32(&dispatcherState) != dispatcherDetached {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&dispatcherState, dispatcherDetached, dispatcherAttaching) {
+		return
+	}
+
+	if err := attachMainThreadDispatcher(); err != nil {
+		// try again the next time a function is queued
+		atomic.CompareAndSwapInt32(&dispatcherState, dispatcherAttaching, dispatcherDetached)
+		dispatcherWarning.Do(func() {
+			Log.Warning(fmt.Sprintf(
+				"gdnative: functions queued with RunOnMain can not run yet, could not add a %s node to the SceneTree: %s. Add it as an AutoLoad to drain the queue from the first frame",
+				MainThreadDispatcherClass, err,
+			))
+		})
+	}
+}
+
+// attachMainThreadDispatcher instances the MainThreadDispatcherClass using
+// the GDNativeLibrary this library was loaded from and adds it to the root of
+// the SceneTree. It can be called from any thread, the node is created
+// outside of the tree and added to it with call_deferred that is thread safe
+func attachMainThreadDispatcher() error {
+
+	if GDNative.library == nil {
+		return fmt.Errorf("the GDNativeLibrary is not available")
+	}
+
+	root, err := sceneTreeRoot()
+	if err != nil {
+		return err
+	}
+	defer root.Destroy()
+
+	node, err := newMainThreadDispatcherNode()
+	if err != nil {
+		return err
+	}
+	defer node.Destroy()
+
+	method := NewVariantWithString(String("add_child"))
+	defer method.Destroy()
+
+	result, err := root.AsObject().Call("call_deferred", method, node)
+	result.Destroy()
+
+	return err
+}
+
+// sceneTreeRoot returns back a Variant holding the root Viewport of the
+// SceneTree, it fails if the main loop does not exist yet or is not a SceneTree
+func sceneTreeRoot() (Variant, error) {
+
+	mainLoop, err := GetSingleton("Engine").Call("get_main_loop")
+	if err != nil {
+		return mainLoop, err
+	}
+	defer mainLoop.Destroy()
+
+	if mainLoop.GetType() != VariantTypeObject || mainLoop.AsObject().getBase() == nil {
+		return NewVariantNil(), fmt.Errorf("there is no main loop yet")
+	}
+
+	root, err := mainLoop.AsObject().Call("get_root")
+	if err != nil {
+		return root, fmt.Errorf("the main loop is not a SceneTree: %w", err)
+	}
+
+	return root, nil
+}
+
+// newMainThreadDispatcherNode creates a new NativeScript for the
+// MainThreadDispatcherClass and returns back a Variant holding a new
+// instance of it
+func newMainThreadDispatcherNode() (Variant, error) {
+
+	className := C.CString("NativeScript")
+	defer C.free(unsafe.Pointer(className))
+
+	constructor := C.go_godot_get_class_constructor(GDNative.api, className)
+	script := Object{base: C.cgo_construct_object(constructor)}
+	if script.getBase() == nil {
+		return NewVariantNil(), fmt.Errorf("NativeScript class constructor returned nil")
+	}
+
+	// NativeScript is a Reference, the Variant keeps it alive while we use it
+	// and the node keeps it alive afterwards
+	scriptVariant := newVariantWithObject(script)
+	defer scriptVariant.Destroy()
+
+	library := NewVariantObject(Object{base: GDNative.library})
+	defer library.Destroy()
+
+	name := NewVariantWithString(String(MainThreadDispatcherClass))
+	defer name.Destroy()
+
+	for _, call := range []struct {
+		method string
+		arg    Variant
+	}{{"set_library", library}, {"set_class_name", name}} {
+		result, err := script.Call(call.method, call.arg)
+		result.Destroy()
+		if err != nil {
+			return NewVariantNil(), err
+		}
+	}
+
+	node, err := script.Call("new")
+	if err != nil {
+		return node, err
+	}
+
+	if node.GetType() != VariantTypeObject || node.AsObject().getBase() == nil {
+		node.Destroy()
+		return NewVariantNil(), fmt.Errorf("could not instance %s", MainThreadDispatcherClass)
+	}
+
+	result, err := node.AsObject().Call("set_name", name)
+	result.Destroy()
+	if err != nil {
+		node.Destroy()
+		return NewVariantNil(), err
+	}
+
+	return node, nil
+}
+
+// resetMainThreadDispatcher forgets the dispatcher node when the library is
+// terminated, a new one is needed if Godot loads the library again
+func resetMainThreadDispatcher() {
+	atomic.StoreInt32(&dispatcherState, dispatcherDetached)
+}
@@ -0,0 +1,1145 @@
+//go:build fakeapi
+
+// Fake of the Godot core API used by the tests built with the fakeapi tag, it
+// implements Variants, Strings, Arrays, Dictionaries and the numeric pool
+// arrays in plain C so the package can be tested without running Godot.
+// Containers are reference counted as they are in Godot: Arrays and
+// Dictionaries are shared, Strings and pool arrays are copied on write.
+// Every container is counted while it is alive so tests can check that the
+// Go side frees what it creates. API functions that are not implemented are
+// left as NULL pointers.
+
+#include "fakeapi.h"
+#include <gdnative/gdnative.h>
+#include <gdnative_api_struct.gen.h>
+#include <math.h>
+#include <stdint.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <wchar.h>
+
+static int live_objects;
+
+// every container starts with its reference count
+typedef struct {
+	int refs;
+} fake_ref;
+
+typedef struct {
+	fake_ref ref;
+	int length;
+	wchar_t *data;
+} fake_string;
+
+typedef struct {
+	fake_ref ref;
+	int size;
+	int capacity;
+	godot_variant *items;
+} fake_array;
+
+typedef struct {
+	fake_ref ref;
+	int size;
+	int capacity;
+	godot_variant *keys;
+	godot_variant *values;
+} fake_dictionary;
+
+typedef struct {
+	fake_ref ref;
+	int element_size;
+	int size;
+	int capacity;
+	uint8_t *data;
+} fake_pool;
+
+typedef struct {
+	int length;
+	char *data;
+} fake_char_string;
+
+typedef struct {
+	fake_pool *pool;
+} fake_pool_access;
+
+// fake_variant is stored into the godot_variant opaque bytes
+typedef struct {
+	godot_variant_type type;
+	union {
+		godot_bool b;
+		int64_t i;
+		double r;
+		void *ptr;
+	} value;
+} fake_variant;
+
+_Static_assert(sizeof(fake_variant) <= sizeof(godot_variant), "fake_variant does not fit in godot_variant");
+
+static void *fake_alloc(size_t size) {
+	__atomic_add_fetch(&live_objects, 1, __ATOMIC_SEQ_CST);
+
+	return calloc(1, size);
+}
+
+// allocates a container with a single reference
+static void *fake_ref_alloc(size_t size) {
+	fake_ref *ref = fake_alloc(size);
+	ref->refs = 1;
+
+	return ref;
+}
+
+static void fake_free(void *ptr) {
+	__atomic_sub_fetch(&live_objects, 1, __ATOMIC_SEQ_CST);
+	free(ptr);
+}
+
+int fake_godot_live_objects() {
+	return __atomic_load_n(&live_objects, __ATOMIC_SEQ_CST);
+}
+
+static void *fake_ref_get(void *ptr) {
+	__atomic_add_fetch(&((fake_ref *)ptr)->refs, 1, __ATOMIC_SEQ_CST);
+
+	return ptr;
+}
+
+// returns true if the last reference was released
+static int fake_ref_put(void *ptr) {
+	return __atomic_sub_fetch(&((fake_ref *)ptr)->refs, 1, __ATOMIC_SEQ_CST) == 0;
+}
+
+// opaque Godot types hold a pointer to the fake container
+static void *load_ptr(const void *opaque) {
+	void *ptr;
+	memcpy(&ptr, opaque, sizeof(ptr));
+
+	return ptr;
+}
+
+static void store_ptr(void *opaque, void *ptr) {
+	memcpy(opaque, &ptr, sizeof(ptr));
+}
+
+static fake_variant load_variant(const godot_variant *variant) {
+	fake_variant value;
+	memcpy(&value, variant, sizeof(value));
+
+	return value;
+}
+
+static void store_variant(godot_variant *variant, fake_variant value) {
+	memset(variant, 0, sizeof(*variant));
+	memcpy(variant, &value, sizeof(value));
+}
+
+static int is_ref_type(godot_variant_type type) {
+	switch (type) {
+	case GODOT_VARIANT_TYPE_STRING:
+	case GODOT_VARIANT_TYPE_DICTIONARY:
+	case GODOT_VARIANT_TYPE_ARRAY:
+	case GODOT_VARIANT_TYPE_POOL_BYTE_ARRAY:
+	case GODOT_VARIANT_TYPE_POOL_INT_ARRAY:
+	case GODOT_VARIANT_TYPE_POOL_REAL_ARRAY:
+		return 1;
+	default:
+		return 0;
+	}
+}
+
+static int is_number(godot_variant_type type) {
+	return type == GODOT_VARIANT_TYPE_INT || type == GODOT_VARIANT_TYPE_REAL;
+}
+
+static double number(fake_variant value) {
+	return value.type == GODOT_VARIANT_TYPE_INT ? (double)value.value.i : value.value.r;
+}
+
+static void fake_variant_destroy(godot_variant *self);
+
+/* Strings */
+
+static fake_string *string_alloc(int capacity) {
+	fake_string *str = fake_ref_alloc(sizeof(fake_string));
+	str->data = calloc(capacity + 1, sizeof(wchar_t));
+
+	return str;
+}
+
+static void string_release(fake_string *str) {
+	if (fake_ref_put(str)) {
+		free(str->data);
+		fake_free(str);
+	}
+}
+
+static int string_compare(const fake_string *a, const fake_string *b) {
+	return wcscmp(a->data, b->data);
+}
+
+// decodes UTF-8 replacing invalid sequences with U+FFFD, as Godot 3 does it
+// stops at the first NUL byte
+static fake_string *string_from_utf8(const char *utf8, int length) {
+	fake_string *str = string_alloc(length);
+	const unsigned char *p = (const unsigned char *)utf8;
+	const unsigned char *end = p + length;
+
+	while (p < end && *p != 0) {
+		uint32_t c = *p++;
+		int extra = 0;
+		if (c < 0x80) {
+			extra = 0;
+		} else if ((c & 0xe0) == 0xc0) {
+			c &= 0x1f;
+			extra = 1;
+		} else if ((c & 0xf0) == 0xe0) {
+			c &= 0x0f;
+			extra = 2;
+		} else if ((c & 0xf8) == 0xf0) {
+			c &= 0x07;
+			extra = 3;
+		} else {
+			c = 0xfffd;
+		}
+
+		for (; extra > 0; extra--) {
+			if (p >= end || (*p & 0xc0) != 0x80) {
+				c = 0xfffd;
+				break;
+			}
+			c = (c << 6) | (*p++ & 0x3f);
+		}
+
+		str->data[str->length++] = (wchar_t)c;
+	}
+
+	return str;
+}
+
+static fake_string *string_from_ascii(const char *text) {
+	return string_from_utf8(text, strlen(text));
+}
+
+static void fake_string_new(godot_string *r_dest) {
+	store_ptr(r_dest, string_alloc(0));
+}
+
+static void fake_string_new_copy(godot_string *r_dest, const godot_string *p_src) {
+	store_ptr(r_dest, fake_ref_get(load_ptr(p_src)));
+}
+
+static void fake_string_new_with_wide_string(godot_string *r_dest, const wchar_t *p_contents, const int p_size) {
+	fake_string *str = string_alloc(p_size);
+	for (int i = 0; i < p_size && p_contents[i] != 0; i++) {
+		str->data[str->length++] = p_contents[i];
+	}
+
+	store_ptr(r_dest, str);
+}
+
+static godot_string fake_string_chars_to_utf8_with_len(const char *p_utf8, godot_int p_length) {
+	godot_string dest;
+	store_ptr(&dest, string_from_utf8(p_utf8, p_length));
+
+	return dest;
+}
+
+static godot_string fake_string_chars_to_utf8(const char *p_utf8) {
+	return fake_string_chars_to_utf8_with_len(p_utf8, strlen(p_utf8));
+}
+
+static godot_int fake_string_length(const godot_string *p_self) {
+	return ((fake_string *)load_ptr(p_self))->length;
+}
+
+static const wchar_t *fake_string_wide_str(const godot_string *p_self) {
+	return ((fake_string *)load_ptr(p_self))->data;
+}
+
+static godot_bool fake_string_operator_equal(const godot_string *p_self, const godot_string *p_b) {
+	return string_compare(load_ptr(p_self), load_ptr(p_b)) == 0;
+}
+
+static godot_bool fake_string_operator_less(const godot_string *p_self, const godot_string *p_b) {
+	return string_compare(load_ptr(p_self), load_ptr(p_b)) < 0;
+}
+
+static void fake_string_destroy(godot_string *p_self) {
+	string_release(load_ptr(p_self));
+}
+
+static int utf8_encode(uint32_t c, char *out) {
+	if (c < 0x80) {
+		out[0] = c;
+		return 1;
+	}
+	if (c < 0x800) {
+		out[0] = 0xc0 | (c >> 6);
+		out[1] = 0x80 | (c & 0x3f);
+		return 2;
+	}
+	if (c < 0x10000) {
+		out[0] = 0xe0 | (c >> 12);
+		out[1] = 0x80 | ((c >> 6) & 0x3f);
+		out[2] = 0x80 | (c & 0x3f);
+		return 3;
+	}
+
+	out[0] = 0xf0 | (c >> 18);
+	out[1] = 0x80 | ((c >> 12) & 0x3f);
+	out[2] = 0x80 | ((c >> 6) & 0x3f);
+	out[3] = 0x80 | (c & 0x3f);
+	return 4;
+}
+
+static godot_char_string fake_string_utf8(const godot_string *p_self) {
+	fake_string *str = load_ptr(p_self);
+	fake_char_string *cs = fake_alloc(sizeof(fake_char_string));
+	cs->data = calloc(str->length * 4 + 1, 1);
+	for (int i = 0; i < str->length; i++) {
+		cs->length += utf8_encode((uint32_t)str->data[i], cs->data + cs->length);
+	}
+
+	godot_char_string dest;
+	store_ptr(&dest, cs);
+
+	return dest;
+}
+
+static godot_int fake_char_string_length(const godot_char_string *p_cs) {
+	return ((fake_char_string *)load_ptr(p_cs))->length;
+}
+
+static const char *fake_char_string_get_data(const godot_char_string *p_cs) {
+	return ((fake_char_string *)load_ptr(p_cs))->data;
+}
+
+static void fake_char_string_destroy(godot_char_string *p_cs) {
+	fake_char_string *cs = load_ptr(p_cs);
+	free(cs->data);
+	fake_free(cs);
+}
+
+/* Arrays */
+
+static fake_array *array_alloc() {
+	return fake_ref_alloc(sizeof(fake_array));
+}
+
+static void array_release(fake_array *array) {
+	if (fake_ref_put(array)) {
+		for (int i = 0; i < array->size; i++) {
+			fake_variant_destroy(&array->items[i]);
+		}
+		free(array->items);
+		fake_free(array);
+	}
+}
+
+static void array_reserve(fake_array *array, int size) {
+	if (size > array->capacity) {
+		array->capacity = size < 2 * array->capacity ? 2 * array->capacity : size;
+		array->items = realloc(array->items, array->capacity * sizeof(godot_variant));
+	}
+}
+
+static void fake_variant_new_copy(godot_variant *r_dest, const godot_variant *p_src);
+
+static void array_push(fake_array *array, const godot_variant *value) {
+	array_reserve(array, array->size + 1);
+	fake_variant_new_copy(&array->items[array->size++], value);
+}
+
+static void fake_array_new(godot_array *r_dest) {
+	store_ptr(r_dest, array_alloc());
+}
+
+static void fake_array_new_copy(godot_array *r_dest, const godot_array *p_src) {
+	store_ptr(r_dest, fake_ref_get(load_ptr(p_src)));
+}
+
+static void fake_array_set(godot_array *p_self, const godot_int p_idx, const godot_variant *p_value) {
+	fake_array *array = load_ptr(p_self);
+	if (p_idx < 0 || p_idx >= array->size) {
+		return;
+	}
+
+	godot_variant old = array->items[p_idx];
+	fake_variant_new_copy(&array->items[p_idx], p_value);
+	fake_variant_destroy(&old);
+}
+
+static godot_variant fake_array_get(const godot_array *p_self, const godot_int p_idx) {
+	fake_array *array = load_ptr(p_self);
+	godot_variant dest;
+	if (p_idx < 0 || p_idx >= array->size) {
+		store_variant(&dest, (fake_variant){.type = GODOT_VARIANT_TYPE_NIL});
+		return dest;
+	}
+
+	fake_variant_new_copy(&dest, &array->items[p_idx]);
+	return dest;
+}
+
+static void fake_array_append(godot_array *p_self, const godot_variant *p_value) {
+	array_push(load_ptr(p_self), p_value);
+}
+
+static void fake_array_clear(godot_array *p_self) {
+	fake_array *array = load_ptr(p_self);
+	for (int i = 0; i < array->size; i++) {
+		fake_variant_destroy(&array->items[i]);
+	}
+	array->size = 0;
+}
+
+static godot_int fake_array_size(const godot_array *p_self) {
+	return ((fake_array *)load_ptr(p_self))->size;
+}
+
+static godot_bool fake_array_empty(const godot_array *p_self) {
+	return fake_array_size(p_self) == 0;
+}
+
+static void fake_array_destroy(godot_array *p_self) {
+	array_release(load_ptr(p_self));
+}
+
+/* Dictionaries */
+
+static godot_bool fake_variant_hash_compare(const godot_variant *p_self, const godot_variant *p_other);
+
+static void dictionary_release(fake_dictionary *dictionary) {
+	if (fake_ref_put(dictionary)) {
+		for (int i = 0; i < dictionary->size; i++) {
+			fake_variant_destroy(&dictionary->keys[i]);
+			fake_variant_destroy(&dictionary->values[i]);
+		}
+		free(dictionary->keys);
+		free(dictionary->values);
+		fake_free(dictionary);
+	}
+}
+
+// returns the position of the given key or -1, keys are compared as Godot
+// does in its hash maps
+static int dictionary_find(const fake_dictionary *dictionary, const godot_variant *key) {
+	for (int i = 0; i < dictionary->size; i++) {
+		if (fake_variant_hash_compare(&dictionary->keys[i], key)) {
+			return i;
+		}
+	}
+
+	return -1;
+}
+
+static void fake_dictionary_new(godot_dictionary *r_dest) {
+	store_ptr(r_dest, fake_ref_alloc(sizeof(fake_dictionary)));
+}
+
+static void fake_dictionary_new_copy(godot_dictionary *r_dest, const godot_dictionary *p_src) {
+	store_ptr(r_dest, fake_ref_get(load_ptr(p_src)));
+}
+
+static void fake_dictionary_destroy(godot_dictionary *p_self) {
+	dictionary_release(load_ptr(p_self));
+}
+
+static godot_int fake_dictionary_size(const godot_dictionary *p_self) {
+	return ((fake_dictionary *)load_ptr(p_self))->size;
+}
+
+static godot_bool fake_dictionary_empty(const godot_dictionary *p_self) {
+	return fake_dictionary_size(p_self) == 0;
+}
+
+static godot_bool fake_dictionary_has(const godot_dictionary *p_self, const godot_variant *p_key) {
+	return dictionary_find(load_ptr(p_self), p_key) >= 0;
+}
+
+static godot_variant fake_dictionary_get(const godot_dictionary *p_self, const godot_variant *p_key) {
+	fake_dictionary *dictionary = load_ptr(p_self);
+	godot_variant dest;
+	int i = dictionary_find(dictionary, p_key);
+	if (i < 0) {
+		store_variant(&dest, (fake_variant){.type = GODOT_VARIANT_TYPE_NIL});
+		return dest;
+	}
+
+	fake_variant_new_copy(&dest, &dictionary->values[i]);
+	return dest;
+}
+
+static void fake_dictionary_set(godot_dictionary *p_self, const godot_variant *p_key, const godot_variant *p_value) {
+	fake_dictionary *dictionary = load_ptr(p_self);
+	int i = dictionary_find(dictionary, p_key);
+	if (i >= 0) {
+		godot_variant old = dictionary->values[i];
+		fake_variant_new_copy(&dictionary->values[i], p_value);
+		fake_variant_destroy(&old);
+		return;
+	}
+
+	if (dictionary->size == dictionary->capacity) {
+		dictionary->capacity = dictionary->capacity == 0 ? 8 : 2 * dictionary->capacity;
+		dictionary->keys = realloc(dictionary->keys, dictionary->capacity * sizeof(godot_variant));
+		dictionary->values = realloc(dictionary->values, dictionary->capacity * sizeof(godot_variant));
+	}
+
+	fake_variant_new_copy(&dictionary->keys[dictionary->size], p_key);
+	fake_variant_new_copy(&dictionary->values[dictionary->size], p_value);
+	dictionary->size++;
+}
+
+static void fake_dictionary_erase(godot_dictionary *p_self, const godot_variant *p_key) {
+	fake_dictionary *dictionary = load_ptr(p_self);
+	int i = dictionary_find(dictionary, p_key);
+	if (i < 0) {
+		return;
+	}
+
+	fake_variant_destroy(&dictionary->keys[i]);
+	fake_variant_destroy(&dictionary->values[i]);
+	memmove(&dictionary->keys[i], &dictionary->keys[i + 1], (dictionary->size - i - 1) * sizeof(godot_variant));
+	memmove(&dictionary->values[i], &dictionary->values[i + 1], (dictionary->size - i - 1) * sizeof(godot_variant));
+	dictionary->size--;
+}
+
+static godot_array fake_dictionary_keys(const godot_dictionary *p_self) {
+	fake_dictionary *dictionary = load_ptr(p_self);
+	fake_array *keys = array_alloc();
+	for (int i = 0; i < dictionary->size; i++) {
+		array_push(keys, &dictionary->keys[i]);
+	}
+
+	godot_array dest;
+	store_ptr(&dest, keys);
+	return dest;
+}
+
+static godot_array fake_dictionary_values(const godot_dictionary *p_self) {
+	fake_dictionary *dictionary = load_ptr(p_self);
+	fake_array *values = array_alloc();
+	for (int i = 0; i < dictionary->size; i++) {
+		array_push(values, &dictionary->values[i]);
+	}
+
+	godot_array dest;
+	store_ptr(&dest, values);
+	return dest;
+}
+
+static void fake_dictionary_clear(godot_dictionary *p_self) {
+	fake_dictionary *dictionary = load_ptr(p_self);
+	for (int i = 0; i < dictionary->size; i++) {
+		fake_variant_destroy(&dictionary->keys[i]);
+		fake_variant_destroy(&dictionary->values[i]);
+	}
+	dictionary->size = 0;
+}
+
+/* Pool arrays */
+
+static fake_pool *pool_alloc(int element_size) {
+	fake_pool *pool = fake_ref_alloc(sizeof(fake_pool));
+	pool->element_size = element_size;
+
+	return pool;
+}
+
+static void pool_release(fake_pool *pool) {
+	if (fake_ref_put(pool)) {
+		free(pool->data);
+		fake_free(pool);
+	}
+}
+
+static void pool_resize(fake_pool *pool, int size) {
+	if (size > pool->capacity) {
+		pool->capacity = size < 2 * pool->capacity ? 2 * pool->capacity : size;
+		pool->data = realloc(pool->data, pool->capacity * pool->element_size);
+	}
+	if (size > pool->size) {
+		memset(pool->data + pool->size * pool->element_size, 0, (size - pool->size) * pool->element_size);
+	}
+	pool->size = size;
+}
+
+// returns the pool held by the given opaque value making it unique first, the
+// copy on write that Godot does before any change
+static fake_pool *pool_own(void *opaque) {
+	fake_pool *pool = load_ptr(opaque);
+	if (__atomic_load_n(&pool->ref.refs, __ATOMIC_SEQ_CST) == 1) {
+		return pool;
+	}
+
+	fake_pool *copy = pool_alloc(pool->element_size);
+	pool_resize(copy, pool->size);
+	if (pool->size > 0) {
+		memcpy(copy->data, pool->data, pool->size * pool->element_size);
+	}
+	pool_release(pool);
+	store_ptr(opaque, copy);
+
+	return copy;
+}
+
+static fake_pool_access *pool_access(fake_pool *pool) {
+	fake_pool_access *access = fake_alloc(sizeof(fake_pool_access));
+	access->pool = fake_ref_get(pool);
+
+	return access;
+}
+
+static void pool_access_destroy(fake_pool_access *access) {
+	pool_release(access->pool);
+	fake_free(access);
+}
+
+#define FAKE_POOL(name, element)                                                                               \
+	static void fake_pool_##name##_array_new(godot_pool_##name##_array *r_dest) {                          \
+		store_ptr(r_dest, pool_alloc(sizeof(element)));                                                \
+	}                                                                                                      \
+	static void fake_pool_##name##_array_new_copy(godot_pool_##name##_array *r_dest,                       \
+						      const godot_pool_##name##_array *p_src) {                \
+		store_ptr(r_dest, fake_ref_get(load_ptr(p_src)));                                              \
+	}                                                                                                      \
+	static void fake_pool_##name##_array_destroy(godot_pool_##name##_array *p_self) {                      \
+		pool_release(load_ptr(p_self));                                                                \
+	}                                                                                                      \
+	static godot_int fake_pool_##name##_array_size(const godot_pool_##name##_array *p_self) {              \
+		return ((fake_pool *)load_ptr(p_self))->size;                                                  \
+	}                                                                                                      \
+	static void fake_pool_##name##_array_resize(godot_pool_##name##_array *p_self, const godot_int p_size) { \
+		pool_resize(pool_own(p_self), p_size);                                                         \
+	}                                                                                                      \
+	static void fake_pool_##name##_array_append(godot_pool_##name##_array *p_self, const element p_data) {  \
+		fake_pool *pool = pool_own(p_self);                                                            \
+		pool_resize(pool, pool->size + 1);                                                             \
+		((element *)pool->data)[pool->size - 1] = p_data;                                              \
+	}                                                                                                      \
+	static void fake_pool_##name##_array_set(godot_pool_##name##_array *p_self, const godot_int p_idx,     \
+						 const element p_data) {                                       \
+		fake_pool *pool = pool_own(p_self);                                                            \
+		if (p_idx >= 0 && p_idx < pool->size) {                                                        \
+			((element *)pool->data)[p_idx] = p_data;                                               \
+		}                                                                                              \
+	}                                                                                                      \
+	static element fake_pool_##name##_array_get(const godot_pool_##name##_array *p_self,                  \
+						    const godot_int p_idx) {                                   \
+		fake_pool *pool = load_ptr(p_self);                                                            \
+		if (p_idx < 0 || p_idx >= pool->size) {                                                        \
+			return 0;                                                                              \
+		}                                                                                              \
+		return ((element *)pool->data)[p_idx];                                                         \
+	}                                                                                                      \
+	static godot_pool_##name##_array_read_access *fake_pool_##name##_array_read(                           \
+		const godot_pool_##name##_array *p_self) {                                                     \
+		return (godot_pool_##name##_array_read_access *)pool_access(load_ptr(p_self));                 \
+	}                                                                                                      \
+	static godot_pool_##name##_array_write_access *fake_pool_##name##_array_write(                         \
+		godot_pool_##name##_array *p_self) {                                                           \
+		return (godot_pool_##name##_array_write_access *)pool_access(pool_own(p_self));                \
+	}                                                                                                      \
+	static const element *fake_pool_##name##_array_read_access_ptr(                                        \
+		const godot_pool_##name##_array_read_access *p_read) {                                         \
+		return (const element *)((const fake_pool_access *)p_read)->pool->data;                        \
+	}                                                                                                      \
+	static element *fake_pool_##name##_array_write_access_ptr(                                             \
+		const godot_pool_##name##_array_write_access *p_write) {                                       \
+		return (element *)((const fake_pool_access *)p_write)->pool->data;                             \
+	}                                                                                                      \
+	static void fake_pool_##name##_array_read_access_destroy(godot_pool_##name##_array_read_access *p_read) { \
+		pool_access_destroy((fake_pool_access *)p_read);                                               \
+	}                                                                                                      \
+	static void fake_pool_##name##_array_write_access_destroy(                                             \
+		godot_pool_##name##_array_write_access *p_write) {                                             \
+		pool_access_destroy((fake_pool_access *)p_write);                                              \
+	}
+
+FAKE_POOL(byte, uint8_t)
+FAKE_POOL(int, godot_int)
+FAKE_POOL(real, godot_real)
+
+// pool_to_array converts the elements of the given numeric pool into an Array
+static fake_array *pool_to_array(const fake_variant value) {
+	fake_array *array = array_alloc();
+	fake_pool *pool = value.value.ptr;
+	for (int i = 0; i < pool->size; i++) {
+		fake_variant element = {.type = GODOT_VARIANT_TYPE_INT};
+		switch (value.type) {
+		case GODOT_VARIANT_TYPE_POOL_BYTE_ARRAY:
+			element.value.i = ((uint8_t *)pool->data)[i];
+			break;
+		case GODOT_VARIANT_TYPE_POOL_INT_ARRAY:
+			element.value.i = ((godot_int *)pool->data)[i];
+			break;
+		default:
+			element.type = GODOT_VARIANT_TYPE_REAL;
+			element.value.r = ((godot_real *)pool->data)[i];
+		}
+
+		godot_variant variant;
+		store_variant(&variant, element);
+		array_push(array, &variant);
+	}
+
+	return array;
+}
+
+/* Variants */
+
+static void fake_variant_new_nil(godot_variant *r_dest) {
+	store_variant(r_dest, (fake_variant){.type = GODOT_VARIANT_TYPE_NIL});
+}
+
+static void fake_variant_new_bool(godot_variant *r_dest, const godot_bool p_b) {
+	store_variant(r_dest, (fake_variant){.type = GODOT_VARIANT_TYPE_BOOL, .value.b = p_b});
+}
+
+static void fake_variant_new_uint(godot_variant *r_dest, const uint64_t p_i) {
+	store_variant(r_dest, (fake_variant){.type = GODOT_VARIANT_TYPE_INT, .value.i = (int64_t)p_i});
+}
+
+static void fake_variant_new_int(godot_variant *r_dest, const int64_t p_i) {
+	store_variant(r_dest, (fake_variant){.type = GODOT_VARIANT_TYPE_INT, .value.i = p_i});
+}
+
+static void fake_variant_new_real(godot_variant *r_dest, const double p_r) {
+	store_variant(r_dest, (fake_variant){.type = GODOT_VARIANT_TYPE_REAL, .value.r = p_r});
+}
+
+static void new_ref_variant(godot_variant *r_dest, godot_variant_type type, const void *opaque) {
+	store_variant(r_dest, (fake_variant){.type = type, .value.ptr = fake_ref_get(load_ptr(opaque))});
+}
+
+static void fake_variant_new_string(godot_variant *r_dest, const godot_string *p_s) {
+	new_ref_variant(r_dest, GODOT_VARIANT_TYPE_STRING, p_s);
+}
+
+static void fake_variant_new_array(godot_variant *r_dest, const godot_array *p_arr) {
+	new_ref_variant(r_dest, GODOT_VARIANT_TYPE_ARRAY, p_arr);
+}
+
+static void fake_variant_new_dictionary(godot_variant *r_dest, const godot_dictionary *p_dict) {
+	new_ref_variant(r_dest, GODOT_VARIANT_TYPE_DICTIONARY, p_dict);
+}
+
+static void fake_variant_new_pool_byte_array(godot_variant *r_dest, const godot_pool_byte_array *p_pba) {
+	new_ref_variant(r_dest, GODOT_VARIANT_TYPE_POOL_BYTE_ARRAY, p_pba);
+}
+
+static void fake_variant_new_pool_int_array(godot_variant *r_dest, const godot_pool_int_array *p_pia) {
+	new_ref_variant(r_dest, GODOT_VARIANT_TYPE_POOL_INT_ARRAY, p_pia);
+}
+
+static void fake_variant_new_pool_real_array(godot_variant *r_dest, const godot_pool_real_array *p_pra) {
+	new_ref_variant(r_dest, GODOT_VARIANT_TYPE_POOL_REAL_ARRAY, p_pra);
+}
+
+static void fake_variant_new_copy(godot_variant *r_dest, const godot_variant *p_src) {
+	fake_variant value = load_variant(p_src);
+	if (is_ref_type(value.type)) {
+		fake_ref_get(value.value.ptr);
+	}
+
+	store_variant(r_dest, value);
+}
+
+static void fake_variant_destroy(godot_variant *p_self) {
+	fake_variant value = load_variant(p_self);
+	switch (value.type) {
+	case GODOT_VARIANT_TYPE_STRING:
+		string_release(value.value.ptr);
+		break;
+	case GODOT_VARIANT_TYPE_ARRAY:
+		array_release(value.value.ptr);
+		break;
+	case GODOT_VARIANT_TYPE_DICTIONARY:
+		dictionary_release(value.value.ptr);
+		break;
+	case GODOT_VARIANT_TYPE_POOL_BYTE_ARRAY:
+	case GODOT_VARIANT_TYPE_POOL_INT_ARRAY:
+	case GODOT_VARIANT_TYPE_POOL_REAL_ARRAY:
+		pool_release(value.value.ptr);
+		break;
+	default:
+		break;
+	}
+
+	fake_variant_new_nil(p_self);
+}
+
+static godot_variant_type fake_variant_get_type(const godot_variant *p_v) {
+	return load_variant(p_v).type;
+}
+
+static godot_bool fake_variant_as_bool(const godot_variant *p_self) {
+	fake_variant value = load_variant(p_self);
+	switch (value.type) {
+	case GODOT_VARIANT_TYPE_BOOL:
+		return value.value.b;
+	case GODOT_VARIANT_TYPE_INT:
+		return value.value.i != 0;
+	case GODOT_VARIANT_TYPE_REAL:
+		return value.value.r != 0;
+	case GODOT_VARIANT_TYPE_STRING:
+		return ((fake_string *)value.value.ptr)->length != 0;
+	default:
+		return false;
+	}
+}
+
+static int64_t fake_variant_as_int(const godot_variant *p_self) {
+	fake_variant value = load_variant(p_self);
+	switch (value.type) {
+	case GODOT_VARIANT_TYPE_BOOL:
+		return value.value.b;
+	case GODOT_VARIANT_TYPE_INT:
+		return value.value.i;
+	case GODOT_VARIANT_TYPE_REAL:
+		return (int64_t)value.value.r;
+	default:
+		return 0;
+	}
+}
+
+static uint64_t fake_variant_as_uint(const godot_variant *p_self) {
+	return (uint64_t)fake_variant_as_int(p_self);
+}
+
+static double fake_variant_as_real(const godot_variant *p_self) {
+	fake_variant value = load_variant(p_self);
+	switch (value.type) {
+	case GODOT_VARIANT_TYPE_BOOL:
+		return value.value.b;
+	case GODOT_VARIANT_TYPE_INT:
+		return (double)value.value.i;
+	case GODOT_VARIANT_TYPE_REAL:
+		return value.value.r;
+	default:
+		return 0;
+	}
+}
+
+static godot_string fake_variant_as_string(const godot_variant *p_self) {
+	fake_variant value = load_variant(p_self);
+	char text[64];
+	switch (value.type) {
+	case GODOT_VARIANT_TYPE_STRING: {
+		godot_string dest;
+		store_ptr(&dest, fake_ref_get(value.value.ptr));
+		return dest;
+	}
+	case GODOT_VARIANT_TYPE_NIL:
+		snprintf(text, sizeof(text), "Null");
+		break;
+	case GODOT_VARIANT_TYPE_BOOL:
+		snprintf(text, sizeof(text), "%s", value.value.b ? "True" : "False");
+		break;
+	case GODOT_VARIANT_TYPE_INT:
+		snprintf(text, sizeof(text), "%lld", (long long)value.value.i);
+		break;
+	case GODOT_VARIANT_TYPE_REAL:
+		snprintf(text, sizeof(text), "%.14g", value.value.r);
+		break;
+	default:
+		snprintf(text, sizeof(text), "[Variant:%d]", (int)value.type);
+	}
+
+	godot_string dest;
+	store_ptr(&dest, string_from_ascii(text));
+	return dest;
+}
+
+static godot_array fake_variant_as_array(const godot_variant *p_self) {
+	fake_variant value = load_variant(p_self);
+	fake_array *array;
+	switch (value.type) {
+	case GODOT_VARIANT_TYPE_ARRAY:
+		array = fake_ref_get(value.value.ptr);
+		break;
+	case GODOT_VARIANT_TYPE_POOL_BYTE_ARRAY:
+	case GODOT_VARIANT_TYPE_POOL_INT_ARRAY:
+	case GODOT_VARIANT_TYPE_POOL_REAL_ARRAY:
+		array = pool_to_array(value);
+		break;
+	default:
+		array = array_alloc();
+	}
+
+	godot_array dest;
+	store_ptr(&dest, array);
+	return dest;
+}
+
+static godot_dictionary fake_variant_as_dictionary(const godot_variant *p_self) {
+	fake_variant value = load_variant(p_self);
+	godot_dictionary dest;
+	if (value.type == GODOT_VARIANT_TYPE_DICTIONARY) {
+		store_ptr(&dest, fake_ref_get(value.value.ptr));
+	} else {
+		fake_dictionary_new(&dest);
+	}
+
+	return dest;
+}
+
+// pool_from_variant returns a new reference to the pool held by the given
+// variant, or a new pool with its elements converted if it holds an Array
+static fake_pool *pool_from_variant(const godot_variant *p_self, godot_variant_type type, int element_size) {
+	fake_variant value = load_variant(p_self);
+	if (value.type == type) {
+		return fake_ref_get(value.value.ptr);
+	}
+
+	fake_pool *pool = pool_alloc(element_size);
+	if (value.type != GODOT_VARIANT_TYPE_ARRAY) {
+		return pool;
+	}
+
+	fake_array *array = value.value.ptr;
+	pool_resize(pool, array->size);
+	for (int i = 0; i < array->size; i++) {
+		switch (type) {
+		case GODOT_VARIANT_TYPE_POOL_BYTE_ARRAY:
+			((uint8_t *)pool->data)[i] = (uint8_t)fake_variant_as_int(&array->items[i]);
+			break;
+		case GODOT_VARIANT_TYPE_POOL_INT_ARRAY:
+			((godot_int *)pool->data)[i] = (godot_int)fake_variant_as_int(&array->items[i]);
+			break;
+		default:
+			((godot_real *)pool->data)[i] = (godot_real)fake_variant_as_real(&array->items[i]);
+		}
+	}
+
+	return pool;
+}
+
+static godot_pool_byte_array fake_variant_as_pool_byte_array(const godot_variant *p_self) {
+	godot_pool_byte_array dest;
+	store_ptr(&dest, pool_from_variant(p_self, GODOT_VARIANT_TYPE_POOL_BYTE_ARRAY, sizeof(uint8_t)));
+
+	return dest;
+}
+
+static godot_pool_int_array fake_variant_as_pool_int_array(const godot_variant *p_self) {
+	godot_pool_int_array dest;
+	store_ptr(&dest, pool_from_variant(p_self, GODOT_VARIANT_TYPE_POOL_INT_ARRAY, sizeof(godot_int)));
+
+	return dest;
+}
+
+static godot_pool_real_array fake_variant_as_pool_real_array(const godot_variant *p_self) {
+	godot_pool_real_array dest;
+	store_ptr(&dest, pool_from_variant(p_self, GODOT_VARIANT_TYPE_POOL_REAL_ARRAY, sizeof(godot_real)));
+
+	return dest;
+}
+
+// Variant == in Godot 3: numbers compare by value, Strings by contents and
+// Arrays, Dictionaries and pools by identity
+static godot_bool fake_variant_operator_equal(const godot_variant *p_self, const godot_variant *p_other) {
+	fake_variant a = load_variant(p_self);
+	fake_variant b = load_variant(p_other);
+	if (is_number(a.type) && is_number(b.type)) {
+		if (a.type == GODOT_VARIANT_TYPE_INT && b.type == GODOT_VARIANT_TYPE_INT) {
+			return a.value.i == b.value.i;
+		}
+		return number(a) == number(b);
+	}
+
+	if (a.type != b.type) {
+		return false;
+	}
+
+	switch (a.type) {
+	case GODOT_VARIANT_TYPE_NIL:
+		return true;
+	case GODOT_VARIANT_TYPE_BOOL:
+		return a.value.b == b.value.b;
+	case GODOT_VARIANT_TYPE_STRING:
+		return string_compare(a.value.ptr, b.value.ptr) == 0;
+	default:
+		return a.value.ptr == b.value.ptr;
+	}
+}
+
+// Variant < in Godot 3: values of different types are ordered by type
+static godot_bool fake_variant_operator_less(const godot_variant *p_self, const godot_variant *p_other) {
+	fake_variant a = load_variant(p_self);
+	fake_variant b = load_variant(p_other);
+	if (is_number(a.type) && is_number(b.type)) {
+		if (a.type == GODOT_VARIANT_TYPE_INT && b.type == GODOT_VARIANT_TYPE_INT) {
+			return a.value.i < b.value.i;
+		}
+		return number(a) < number(b);
+	}
+
+	if (a.type != b.type) {
+		return a.type < b.type;
+	}
+
+	switch (a.type) {
+	case GODOT_VARIANT_TYPE_BOOL:
+		return a.value.b < b.value.b;
+	case GODOT_VARIANT_TYPE_STRING:
+		return string_compare(a.value.ptr, b.value.ptr) < 0;
+	default:
+		return false;
+	}
+}
+
+// the comparison used by Godot hash maps (Dictionary keys): values of
+// different types are never equal and NaN equals NaN
+static godot_bool fake_variant_hash_compare(const godot_variant *p_self, const godot_variant *p_other) {
+	fake_variant a = load_variant(p_self);
+	fake_variant b = load_variant(p_other);
+	if (a.type != b.type) {
+		return false;
+	}
+
+	switch (a.type) {
+	case GODOT_VARIANT_TYPE_NIL:
+		return true;
+	case GODOT_VARIANT_TYPE_BOOL:
+		return a.value.b == b.value.b;
+	case GODOT_VARIANT_TYPE_INT:
+		return a.value.i == b.value.i;
+	case GODOT_VARIANT_TYPE_REAL:
+		return a.value.r == b.value.r || (isnan(a.value.r) && isnan(b.value.r));
+	case GODOT_VARIANT_TYPE_STRING:
+		return string_compare(a.value.ptr, b.value.ptr) == 0;
+	case GODOT_VARIANT_TYPE_ARRAY: {
+		fake_array *x = a.value.ptr;
+		fake_array *y = b.value.ptr;
+		if (x->size != y->size) {
+			return false;
+		}
+		for (int i = 0; i < x->size; i++) {
+			if (!fake_variant_hash_compare(&x->items[i], &y->items[i])) {
+				return false;
+			}
+		}
+		return true;
+	}
+	default:
+		return a.value.ptr == b.value.ptr;
+	}
+}
+
+/* Output */
+
+static void fake_print(const godot_string *p_message) {
+	godot_char_string message = fake_string_utf8(p_message);
+	fprintf(stderr, "%s\n", fake_char_string_get_data(&message));
+	fake_char_string_destroy(&message);
+}
+
+static void fake_print_warning(const char *p_description, const char *p_function, const char *p_file, int p_line) {
+	fprintf(stderr, "WARNING: %s: %s (%s:%d)\n", p_function, p_description, p_file, p_line);
+}
+
+static void fake_print_error(const char *p_description, const char *p_function, const char *p_file, int p_line) {
+	fprintf(stderr, "ERROR: %s: %s (%s:%d)\n", p_function, p_description, p_file, p_line);
+}
+
+#define FAKE_BIND(name) api.godot_##name = fake_##name
+#define FAKE_BIND_POOL(name)                                   \
+	FAKE_BIND(pool_##name##_array_new);                    \
+	FAKE_BIND(pool_##name##_array_new_copy);               \
+	FAKE_BIND(pool_##name##_array_destroy);                \
+	FAKE_BIND(pool_##name##_array_size);                   \
+	FAKE_BIND(pool_##name##_array_resize);                 \
+	FAKE_BIND(pool_##name##_array_append);                 \
+	FAKE_BIND(pool_##name##_array_set);                    \
+	FAKE_BIND(pool_##name##_array_get);                    \
+	FAKE_BIND(pool_##name##_array_read);                   \
+	FAKE_BIND(pool_##name##_array_write);                  \
+	FAKE_BIND(pool_##name##_array_read_access_ptr);        \
+	FAKE_BIND(pool_##name##_array_write_access_ptr);       \
+	FAKE_BIND(pool_##name##_array_read_access_destroy);    \
+	FAKE_BIND(pool_##name##_array_write_access_destroy)
+
+// Returns the fake core API, it has no extensions nor newer API versions
+godot_gdnative_core_api_struct *fake_godot_api() {
+	static godot_gdnative_core_api_struct api;
+
+	FAKE_BIND(string_new);
+	FAKE_BIND(string_new_copy);
+	FAKE_BIND(string_new_with_wide_string);
+	FAKE_BIND(string_chars_to_utf8);
+	FAKE_BIND(string_chars_to_utf8_with_len);
+	FAKE_BIND(string_length);
+	FAKE_BIND(string_wide_str);
+	FAKE_BIND(string_operator_equal);
+	FAKE_BIND(string_operator_less);
+	FAKE_BIND(string_utf8);
+	FAKE_BIND(string_destroy);
+	FAKE_BIND(char_string_length);
+	FAKE_BIND(char_string_get_data);
+	FAKE_BIND(char_string_destroy);
+
+	FAKE_BIND(array_new);
+	FAKE_BIND(array_new_copy);
+	FAKE_BIND(array_set);
+	FAKE_BIND(array_get);
+	FAKE_BIND(array_append);
+	FAKE_BIND(array_clear);
+	FAKE_BIND(array_size);
+	FAKE_BIND(array_empty);
+	FAKE_BIND(array_destroy);
+
+	FAKE_BIND(dictionary_new);
+	FAKE_BIND(dictionary_new_copy);
+	FAKE_BIND(dictionary_destroy);
+	FAKE_BIND(dictionary_size);
+	FAKE_BIND(dictionary_empty);
+	FAKE_BIND(dictionary_has);
+	FAKE_BIND(dictionary_get);
+	FAKE_BIND(dictionary_set);
+	FAKE_BIND(dictionary_erase);
+	FAKE_BIND(dictionary_keys);
+	FAKE_BIND(dictionary_values);
+	FAKE_BIND(dictionary_clear);
+
+	FAKE_BIND_POOL(byte);
+	FAKE_BIND_POOL(int);
+	FAKE_BIND_POOL(real);
+
+	FAKE_BIND(variant_new_nil);
+	FAKE_BIND(variant_new_bool);
+	FAKE_BIND(variant_new_uint);
+	FAKE_BIND(variant_new_int);
+	FAKE_BIND(variant_new_real);
+	FAKE_BIND(variant_new_string);
+	FAKE_BIND(variant_new_array);
+	FAKE_BIND(variant_new_dictionary);
+	FAKE_BIND(variant_new_pool_byte_array);
+	FAKE_BIND(variant_new_pool_int_array);
+	FAKE_BIND(variant_new_pool_real_array);
+	FAKE_BIND(variant_new_copy);
+	FAKE_BIND(variant_destroy);
+	FAKE_BIND(variant_get_type);
+	FAKE_BIND(variant_as_bool);
+	FAKE_BIND(variant_as_int);
+	FAKE_BIND(variant_as_uint);
+	FAKE_BIND(variant_as_real);
+	FAKE_BIND(variant_as_string);
+	FAKE_BIND(variant_as_array);
+	FAKE_BIND(variant_as_dictionary);
+	FAKE_BIND(variant_as_pool_byte_array);
+	FAKE_BIND(variant_as_pool_int_array);
+	FAKE_BIND(variant_as_pool_real_array);
+	FAKE_BIND(variant_operator_equal);
+	FAKE_BIND(variant_operator_less);
+	FAKE_BIND(variant_hash_compare);
+
+	FAKE_BIND(print);
+	FAKE_BIND(print_warning);
+	FAKE_BIND(print_error);
+
+	return &api;
+}
@@ -0,0 +1,59 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+//go:build fakeapi
+
+package gdnative
+
+/*
+#include "fakeapi.h"
+#include "gdnative.gen.h"
+*/
+import "C"
+
+// Tests that need the Godot API are built with the fakeapi tag, cgo can not
+// be used from _test.go files so the fake API lives in fakeapi.c and is
+// installed from here. Like the rest of the package it needs the
+// godot_headers submodule and the generated bindings:
+//
+//     git submodule update --init
+//     go run build/mage.go generate
+//     go test -tags fakeapi ./gdnative/...
+//
+// The fake only implements Variants, Strings, Arrays, Dictionaries and the
+// byte, int and real pool arrays, calling any other Godot API crashes.
+
+// installFakeAPI makes the package use the fake Godot API as if Godot had
+// initialized the library with it
+func installFakeAPI() {
+	GDNative.api = C.fake_godot_api()
+	GDNative.api11 = nil
+	GDNative.initialized.Store(true)
+}
+
+// fakeLiveObjects returns back the number of engine containers allocated by
+// the fake API that have not been released yet
+func fakeLiveObjects() int {
+	return int(C.fake_godot_live_objects())
+}
+
+// godotStringRoundTrip converts the given Go string into a godot_string and
+// back, it also returns back the length in characters of the godot_string
+func godotStringRoundTrip(value string) (string, int) {
+	godotString := stringAsGodotString(value)
+	defer C.go_godot_string_destroy(GDNative.api, godotString)
+
+	return godotStringAsString(godotString), int(C.go_godot_string_length(GDNative.api, godotString))
+}
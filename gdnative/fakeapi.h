@@ -0,0 +1,10 @@
+#ifndef CGDNATIVE_FAKEAPI_H
+#define CGDNATIVE_FAKEAPI_H
+
+#include <gdnative_api_struct.gen.h>
+
+/* Fake Godot core API used by the tests built with the fakeapi tag */
+godot_gdnative_core_api_struct *fake_godot_api();
+int fake_godot_live_objects();
+
+#endif
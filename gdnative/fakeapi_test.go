@@ -0,0 +1,41 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+//go:build fakeapi
+
+package gdnative
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	installFakeAPI()
+	os.Exit(m.Run())
+}
+
+// checkLeaks fails the test if it finishes with more engine containers
+// alive than it started with
+func checkLeaks(t testing.TB) {
+	t.Helper()
+
+	live := fakeLiveObjects()
+	t.Cleanup(func() {
+		if leaked := fakeLiveObjects() - live; leaked != 0 {
+			t.Errorf("%d engine objects leaked", leaked)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+/*
+#include <gdnative/gdnative.h>
+#include "gdnative.gen.h"
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"gitlab.com/pimpam-games-studio/gdnative-go/gdnative/gdmath"
+)
+
+// The helpers on this file convert the math builtins from and into the pure
+// Go types of the gdmath package with a single copy, the gdmath types share
+// the memory layout of the Godot structs so math heavy code can do all its
+// work in Go and only cross cgo once to get its input and give its result
+
+// the sizes of the Godot structs and the gdmath types must match, any
+// difference overflows one of the array lengths and fails the build
+var (
+	_ [unsafe.Sizeof(C.godot_vector2{}) - unsafe.Sizeof(gdmath.Vector2{})]byte
+	_ [unsafe.Sizeof(gdmath.Vector2{}) - unsafe.Sizeof(C.godot_vector2{})]byte
+	_ [unsafe.Sizeof(C.godot_vector3{}) - unsafe.Sizeof(gdmath.Vector3{})]byte
+	_ [unsafe.Sizeof(gdmath.Vector3{}) - unsafe.Sizeof(C.godot_vector3{})]byte
+	_ [unsafe.Sizeof(C.godot_transform2d{}) - unsafe.Sizeof(gdmath.Transform2D{})]byte
+	_ [unsafe.Sizeof(gdmath.Transform2D{}) - unsafe.Sizeof(C.godot_transform2d{})]byte
+	_ [unsafe.Sizeof(C.godot_basis{}) - unsafe.Sizeof(gdmath.Basis{})]byte
+	_ [unsafe.Sizeof(gdmath.Basis{}) - unsafe.Sizeof(C.godot_basis{})]byte
+	_ [unsafe.Sizeof(C.godot_quat{}) - unsafe.Sizeof(gdmath.Quat{})]byte
+	_ [unsafe.Sizeof(gdmath.Quat{}) - unsafe.Sizeof(C.godot_quat{})]byte
+)
+
+// NewVector2FromMath creates a new Vector2 holding a copy of the given gdmath.Vector2
+func NewVector2FromMath(value gdmath.Vector2) Vector2 {
+	var dest C.godot_vector2
+	*(*gdmath.Vector2)(unsafe.Pointer(&dest)) = value
+	return Vector2{base: &dest}
+}
+
+// AsMath returns back a gdmath.Vector2 copy of this Vector2
+func (gdt Vector2) AsMath() gdmath.Vector2 {
+	if gdt.base == nil {
+		return gdmath.Vector2{}
+	}
+
+	return *(*gdmath.Vector2)(unsafe.Pointer(gdt.base))
+}
+
+// NewVector3FromMath creates a new Vector3 holding a copy of the given gdmath.Vector3
+func NewVector3FromMath(value gdmath.Vector3) Vector3 {
+	var dest C.godot_vector3
+	*(*gdmath.Vector3)(unsafe.Pointer(&dest)) = value
+	return Vector3{base: &dest}
+}
+
+// AsMath returns back a gdmath.Vector3 copy of this Vector3
+func (gdt Vector3) AsMath() gdmath.Vector3 {
+	if gdt.base == nil {
+		return gdmath.Vector3{}
+	}
+
+	return *(*gdmath.Vector3)(unsafe.Pointer(gdt.base))
+}
+
+// NewTransform2DFromMath creates a new Transform2D holding a copy of the given gdmath.Transform2D
+func NewTransform2DFromMath(value gdmath.Transform2D) Transform2D {
+	var dest C.godot_transform2d
+	*(*gdmath.Transform2D)(unsafe.Pointer(&dest)) = value
+	return Transform2D{base: &dest}
+}
+
+// AsMath returns back a gdmath.Transform2D copy of this Transform2D
+func (gdt Transform2D) AsMath() gdmath.Transform2D {
+	if gdt.base == nil {
+		return gdmath.Transform2D{}
+	}
+
+	return *(*gdmath.Transform2D)(unsafe.Pointer(gdt.base))
+}
+
+// NewBasisFromMath creates a new Basis holding a copy of the given gdmath.Basis
+func NewBasisFromMath(value gdmath.Basis) Basis {
+	var dest C.godot_basis
+	*(*gdmath.Basis)(unsafe.Pointer(&dest)) = value
+	return Basis{base: &dest}
+}
+
+// AsMath returns back a gdmath.Basis copy of this Basis
+func (gdt Basis) AsMath() gdmath.Basis {
+	if gdt.base == nil {
+		return gdmath.Basis{}
+	}
+
+	return *(*gdmath.Basis)(unsafe.Pointer(gdt.base))
+}
+
+// NewQuatFromMath creates a new Quat holding a copy of the given gdmath.Quat
+func NewQuatFromMath(value gdmath.Quat) Quat {
+	var dest C.godot_quat
+	*(*gdmath.Quat)(unsafe.Pointer(&dest)) = value
+	return Quat{base: &dest}
+}
+
+// AsMath returns back a gdmath.Quat copy of this Quat
+func (gdt Quat) AsMath() gdmath.Quat {
+	if gdt.base == nil {
+		return gdmath.Quat{}
+	}
+
+	return *(*gdmath.Quat)(unsafe.Pointer(gdt.base))
+}
@@ -0,0 +1,328 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdmath
+
+// Basis is a 3x3 matrix stored as three rows, its columns are the X, Y and
+// Z axes of the transformed space
+type Basis struct {
+	Elements [3]Vector3
+}
+
+// BasisIdentity returns back the identity Basis
+func BasisIdentity() Basis {
+	return Basis{Elements: [3]Vector3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}}
+}
+
+// NewBasisFromAxes creates a new Basis with the given X, Y and Z axes as
+// columns
+func NewBasisFromAxes(x, y, z Vector3) Basis {
+	return Basis{Elements: [3]Vector3{
+		{x.X, y.X, z.X},
+		{x.Y, y.Y, z.Y},
+		{x.Z, y.Z, z.Z},
+	}}
+}
+
+// NewBasisFromAxisAngle creates a new Basis that rotates around the given
+// normalized axis by the given angle in radians
+func NewBasisFromAxisAngle(axis Vector3, phi float32) Basis {
+
+	var m [3][3]float32
+	squared := axis.Mul(axis)
+	cosine := cos(phi)
+	m[0][0] = squared.X + cosine*(1-squared.X)
+	m[1][1] = squared.Y + cosine*(1-squared.Y)
+	m[2][2] = squared.Z + cosine*(1-squared.Z)
+
+	sine := sin(phi)
+	t := 1 - cosine
+
+	xyzt := axis.X * axis.Y * t
+	zyxs := axis.Z * sine
+	m[0][1] = xyzt - zyxs
+	m[1][0] = xyzt + zyxs
+
+	xyzt = axis.X * axis.Z * t
+	zyxs = axis.Y * sine
+	m[0][2] = xyzt + zyxs
+	m[2][0] = xyzt - zyxs
+
+	xyzt = axis.Y * axis.Z * t
+	zyxs = axis.X * sine
+	m[1][2] = xyzt - zyxs
+	m[2][1] = xyzt + zyxs
+
+	return basisFromMatrix(m)
+}
+
+// NewBasisFromEuler creates a new Basis from the given Euler angles in
+// radians using the YXZ convention
+func NewBasisFromEuler(euler Vector3) Basis {
+
+	c, s := cos(euler.X), sin(euler.X)
+	xmat := Basis{Elements: [3]Vector3{{1, 0, 0}, {0, c, -s}, {0, s, c}}}
+
+	c, s = cos(euler.Y), sin(euler.Y)
+	ymat := Basis{Elements: [3]Vector3{{c, 0, s}, {0, 1, 0}, {-s, 0, c}}}
+
+	c, s = cos(euler.Z), sin(euler.Z)
+	zmat := Basis{Elements: [3]Vector3{{c, -s, 0}, {s, c, 0}, {0, 0, 1}}}
+
+	return ymat.Mul(xmat).Mul(zmat)
+}
+
+// NewBasisFromQuat creates a new rotation Basis from the given Quat
+func NewBasisFromQuat(q Quat) Basis {
+
+	s := 2 / q.LengthSquared()
+	xs, ys, zs := q.X*s, q.Y*s, q.Z*s
+	wx, wy, wz := q.W*xs, q.W*ys, q.W*zs
+	xx, xy, xz := q.X*xs, q.X*ys, q.X*zs
+	yy, yz, zz := q.Y*ys, q.Y*zs, q.Z*zs
+
+	return Basis{Elements: [3]Vector3{
+		{1 - (yy + zz), xy - wz, xz + wy},
+		{xy + wz, 1 - (xx + zz), yz - wx},
+		{xz - wy, yz + wx, 1 - (xx + yy)},
+	}}
+}
+
+// basisFromMatrix creates a new Basis from the given row major matrix
+func basisFromMatrix(m [3][3]float32) Basis {
+	return Basis{Elements: [3]Vector3{
+		{m[0][0], m[0][1], m[0][2]},
+		{m[1][0], m[1][1], m[1][2]},
+		{m[2][0], m[2][1], m[2][2]},
+	}}
+}
+
+// matrix returns back the Basis as a row major matrix
+func (b Basis) matrix() [3][3]float32 {
+	return [3][3]float32{
+		{b.Elements[0].X, b.Elements[0].Y, b.Elements[0].Z},
+		{b.Elements[1].X, b.Elements[1].Y, b.Elements[1].Z},
+		{b.Elements[2].X, b.Elements[2].Y, b.Elements[2].Z},
+	}
+}
+
+// Axis returns back the column at the given axis index
+func (b Basis) Axis(axis int) Vector3 {
+	return Vector3{b.Elements[0].Axis(axis), b.Elements[1].Axis(axis), b.Elements[2].Axis(axis)}
+}
+
+// tdotx, tdoty and tdotz return back the dot product of the given vector
+// with the X, Y and Z columns of the Basis
+func (b Basis) tdotx(v Vector3) float32 {
+	return b.Elements[0].X*v.X + b.Elements[1].X*v.Y + b.Elements[2].X*v.Z
+}
+
+func (b Basis) tdoty(v Vector3) float32 {
+	return b.Elements[0].Y*v.X + b.Elements[1].Y*v.Y + b.Elements[2].Y*v.Z
+}
+
+func (b Basis) tdotz(v Vector3) float32 {
+	return b.Elements[0].Z*v.X + b.Elements[1].Z*v.Y + b.Elements[2].Z*v.Z
+}
+
+// Mul returns back the product of both matrices
+func (b Basis) Mul(other Basis) Basis {
+
+	var result Basis
+	for i, row := range b.Elements {
+		result.Elements[i] = Vector3{other.tdotx(row), other.tdoty(row), other.tdotz(row)}
+	}
+
+	return result
+}
+
+// Xform returns back the given vector transformed by the Basis
+func (b Basis) Xform(v Vector3) Vector3 {
+	return Vector3{b.Elements[0].Dot(v), b.Elements[1].Dot(v), b.Elements[2].Dot(v)}
+}
+
+// XformInv returns back the given vector transformed by the transposed
+// Basis, that is the inverse transformation for rotation matrices
+func (b Basis) XformInv(v Vector3) Vector3 {
+	return Vector3{b.tdotx(v), b.tdoty(v), b.tdotz(v)}
+}
+
+// Determinant returns back the determinant of the Basis
+func (b Basis) Determinant() float32 {
+
+	m := b.matrix()
+	return m[0][0]*(m[1][1]*m[2][2]-m[2][1]*m[1][2]) -
+		m[1][0]*(m[0][1]*m[2][2]-m[2][1]*m[0][2]) +
+		m[2][0]*(m[0][1]*m[1][2]-m[1][1]*m[0][2])
+}
+
+// Inverse returns back the inverse of the Basis, like Godot a singular
+// Basis is returned unchanged
+func (b Basis) Inverse() Basis {
+
+	m := b.matrix()
+	cofac := func(row1, col1, row2, col2 int) float32 {
+		return m[row1][col1]*m[row2][col2] - m[row1][col2]*m[row2][col1]
+	}
+
+	co := [3]float32{cofac(1, 1, 2, 2), cofac(1, 2, 2, 0), cofac(1, 0, 2, 1)}
+	det := m[0][0]*co[0] + m[0][1]*co[1] + m[0][2]*co[2]
+	if det == 0 {
+		return b
+	}
+
+	s := 1 / det
+	return Basis{Elements: [3]Vector3{
+		{co[0] * s, cofac(0, 2, 2, 1) * s, cofac(0, 1, 1, 2) * s},
+		{co[1] * s, cofac(0, 0, 2, 2) * s, cofac(0, 2, 1, 0) * s},
+		{co[2] * s, cofac(0, 1, 2, 0) * s, cofac(0, 0, 1, 1) * s},
+	}}
+}
+
+// Transposed returns back the transposed Basis
+func (b Basis) Transposed() Basis {
+	return NewBasisFromAxes(b.Elements[0], b.Elements[1], b.Elements[2])
+}
+
+// Orthonormalized returns back the Basis with its axes orthogonal and
+// normalized using Gram-Schmidt
+func (b Basis) Orthonormalized() Basis {
+
+	x := b.Axis(Vector3AxisX).Normalized()
+	y := b.Axis(Vector3AxisY)
+	z := b.Axis(Vector3AxisZ)
+
+	y = y.Sub(x.Scale(x.Dot(y))).Normalized()
+	z = z.Sub(x.Scale(x.Dot(z))).Sub(y.Scale(y.Dot(z))).Normalized()
+
+	return NewBasisFromAxes(x, y, z)
+}
+
+// Rotated returns back the Basis rotated around the given normalized axis
+// by the given angle in radians
+func (b Basis) Rotated(axis Vector3, phi float32) Basis {
+	return NewBasisFromAxisAngle(axis, phi).Mul(b)
+}
+
+// Scaled returns back the Basis scaled by the given per axis factors
+func (b Basis) Scaled(scale Vector3) Basis {
+	return Basis{Elements: [3]Vector3{
+		b.Elements[0].Scale(scale.X),
+		b.Elements[1].Scale(scale.Y),
+		b.Elements[2].Scale(scale.Z),
+	}}
+}
+
+// Scale returns back the length of each axis, negated if the Basis flips
+// the handedness of the space
+func (b Basis) Scale() Vector3 {
+
+	return Vector3{
+		b.Axis(Vector3AxisX).Length(),
+		b.Axis(Vector3AxisY).Length(),
+		b.Axis(Vector3AxisZ).Length(),
+	}.Scale(sign(b.Determinant()))
+}
+
+// Euler returns back the rotation of the Basis as Euler angles in radians
+// using the YXZ convention
+func (b Basis) Euler() Vector3 {
+
+	var euler Vector3
+	m := b.matrix()
+	m12 := m[1][2]
+
+	switch {
+	case m12 >= 1-CMPEpsilon:
+		euler.X = -pi * 0.5
+		euler.Y = -atan2(m[0][1], m[0][0])
+	case m12 <= -(1 - CMPEpsilon):
+		euler.X = pi * 0.5
+		euler.Y = atan2(m[0][1], m[0][0])
+	case m[1][0] == 0 && m[0][1] == 0 && m[0][2] == 0 && m[2][0] == 0 && m[0][0] == 1:
+		// a pure X rotation, return the simplest form
+		euler.X = atan2(-m12, m[1][1])
+	default:
+		euler.X = asin(-m12)
+		euler.Y = atan2(m[0][2], m[2][2])
+		euler.Z = atan2(m[1][0], m[1][1])
+	}
+
+	return euler
+}
+
+// Quat returns back the rotation of the Basis as a Quat, the Basis is
+// orthonormalized first
+func (b Basis) Quat() Quat {
+
+	b = b.Orthonormalized()
+	if b.Determinant() < 0 {
+		b = b.Scaled(Vector3{-1, -1, -1})
+	}
+
+	m := b.matrix()
+	var temp [4]float32
+	trace := m[0][0] + m[1][1] + m[2][2]
+	if trace > 0 {
+		s := sqrt(trace + 1)
+		temp[3] = s * 0.5
+		s = 0.5 / s
+		temp[0] = (m[2][1] - m[1][2]) * s
+		temp[1] = (m[0][2] - m[2][0]) * s
+		temp[2] = (m[1][0] - m[0][1]) * s
+	} else {
+		var i int
+		if m[0][0] < m[1][1] {
+			i = 1
+			if m[1][1] < m[2][2] {
+				i = 2
+			}
+		} else if m[0][0] < m[2][2] {
+			i = 2
+		}
+		j := (i + 1) % 3
+		k := (i + 2) % 3
+
+		s := sqrt(m[i][i] - m[j][j] - m[k][k] + 1)
+		temp[i] = s * 0.5
+		s = 0.5 / s
+		temp[3] = (m[k][j] - m[j][k]) * s
+		temp[j] = (m[j][i] + m[i][j]) * s
+		temp[k] = (m[k][i] + m[i][k]) * s
+	}
+
+	return Quat{temp[0], temp[1], temp[2], temp[3]}
+}
+
+// Slerp returns back the spherical linear interpolation between the
+// rotations of both matrices, their scales are interpolated linearly
+func (b Basis) Slerp(to Basis, weight float32) Basis {
+
+	result := NewBasisFromQuat(b.Quat().Slerp(to.Quat(), weight))
+	for i := range result.Elements {
+		result.Elements[i] = result.Elements[i].Scale(
+			Lerp(b.Elements[i].Length(), to.Elements[i].Length(), weight),
+		)
+	}
+
+	return result
+}
+
+// IsEqualApprox returns true if both matrices are approximately equal
+func (b Basis) IsEqualApprox(other Basis) bool {
+	return b.Elements[0].IsEqualApprox(other.Elements[0]) &&
+		b.Elements[1].IsEqualApprox(other.Elements[1]) &&
+		b.Elements[2].IsEqualApprox(other.Elements[2])
+}
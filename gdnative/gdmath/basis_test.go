@@ -0,0 +1,168 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdmath
+
+import "testing"
+
+// expected values are the ones Godot 3.x gives back for the same operations
+
+var eulerBasis = Basis{Elements: [3]Vector3{
+	{0.7624849, -0.4569914, 0.4580127},
+	{0.6154447, 0.7306816, -0.2955202},
+	{-0.1996113, 0.5072112, 0.8383866},
+}}
+
+func TestBasisConstructors(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		basis Basis
+		want  Basis
+	}{
+		{"axis angle X", NewBasisFromAxisAngle(Vector3{1, 0, 0}, pi/2), Basis{Elements: [3]Vector3{{1, 0, 0}, {0, 0, -1}, {0, 1, 0}}}},
+		{"axis angle Y", NewBasisFromAxisAngle(Vector3{0, 1, 0}, pi/2), Basis{Elements: [3]Vector3{{0, 0, 1}, {0, 1, 0}, {-1, 0, 0}}}},
+		{"axis angle Z", NewBasisFromAxisAngle(Vector3{0, 0, 1}, pi/2), Basis{Elements: [3]Vector3{{0, -1, 0}, {1, 0, 0}, {0, 0, 1}}}},
+		{"euler", NewBasisFromEuler(Vector3{0.3, 0.5, 0.7}), eulerBasis},
+		{"quat", NewBasisFromQuat(Quat{0.2198958, 0.1801459, 0.2937772, 0.9126271}), eulerBasis},
+		{"axes", NewBasisFromAxes(Vector3{1, 2, 3}, Vector3{4, 5, 6}, Vector3{7, 8, 9}), Basis{Elements: [3]Vector3{{1, 4, 7}, {2, 5, 8}, {3, 6, 9}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !test.basis.IsEqualApprox(test.want) {
+				t.Errorf("got %v; want %v", test.basis, test.want)
+			}
+		})
+	}
+}
+
+func TestBasisXform(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		basis    Basis
+		vector   Vector3
+		xform    Vector3
+		xformInv Vector3
+	}{
+		{"identity", BasisIdentity(), Vector3{1, 2, 3}, Vector3{1, 2, 3}, Vector3{1, 2, 3}},
+		{"rotation Y", NewBasisFromAxisAngle(Vector3{0, 1, 0}, pi/2), Vector3{1, 0, 0}, Vector3{0, 0, -1}, Vector3{0, 0, 1}},
+		{"euler", eulerBasis, Vector3{1, 2, 3}, Vector3{1.2225402, 1.1902473, 3.329971}, Vector3{1.3945404, 2.5260054, 2.3821322}},
+		{"scale", BasisIdentity().Scaled(Vector3{2, 3, 4}), Vector3{1, 1, 1}, Vector3{2, 3, 4}, Vector3{2, 3, 4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.basis.Xform(test.vector); !got.IsEqualApprox(test.xform) {
+				t.Errorf("Xform = %v; want %v", got, test.xform)
+			}
+			if got := test.basis.XformInv(test.vector); !got.IsEqualApprox(test.xformInv) {
+				t.Errorf("XformInv = %v; want %v", got, test.xformInv)
+			}
+		})
+	}
+}
+
+func TestBasisInverse(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		basis       Basis
+		inverse     Basis
+		determinant float32
+	}{
+		{"identity", BasisIdentity(), BasisIdentity(), 1},
+		{"scale", BasisIdentity().Scaled(Vector3{2, 4, 8}), Basis{Elements: [3]Vector3{{0.5, 0, 0}, {0, 0.25, 0}, {0, 0, 0.125}}}, 64},
+		{"rotation", NewBasisFromAxisAngle(Vector3{0, 0, 1}, pi/2), NewBasisFromAxisAngle(Vector3{0, 0, 1}, -pi/2), 1},
+		{
+			"general",
+			Basis{Elements: [3]Vector3{{1, 2, 3}, {0, 1, 4}, {5, 6, 0}}},
+			Basis{Elements: [3]Vector3{{-24, 18, 5}, {20, -15, -4}, {-5, 4, 1}}},
+			1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.basis.Determinant(); !IsEqualApprox(got, test.determinant) {
+				t.Errorf("Determinant = %v; want %v", got, test.determinant)
+			}
+			if got := test.basis.Inverse(); !got.IsEqualApprox(test.inverse) {
+				t.Errorf("Inverse = %v; want %v", got, test.inverse)
+			}
+			if got := test.basis.Mul(test.basis.Inverse()); !got.IsEqualApprox(BasisIdentity()) {
+				t.Errorf("basis * Inverse = %v; want identity", got)
+			}
+		})
+	}
+}
+
+func TestBasisOperations(t *testing.T) {
+
+	rotationY := NewBasisFromAxisAngle(Vector3{0, 1, 0}, pi/2)
+	tests := []struct {
+		name string
+		got  Basis
+		want Basis
+	}{
+		{"mul", rotationY.Mul(rotationY), NewBasisFromAxisAngle(Vector3{0, 1, 0}, pi)},
+		{"transposed", rotationY.Transposed(), NewBasisFromAxisAngle(Vector3{0, 1, 0}, -pi/2)},
+		{"rotated", BasisIdentity().Rotated(Vector3{0, 1, 0}, pi/2), rotationY},
+		{"scaled", rotationY.Scaled(Vector3{2, 1, 1}), Basis{Elements: [3]Vector3{{0, 0, 2}, {0, 1, 0}, {-1, 0, 0}}}},
+		{"orthonormalized", rotationY.Scaled(Vector3{2, 3, 4}).Orthonormalized(), rotationY},
+		{"slerp", BasisIdentity().Slerp(rotationY, 0.5), NewBasisFromAxisAngle(Vector3{0, 1, 0}, pi/4)},
+		{"slerp start", BasisIdentity().Slerp(rotationY, 0), BasisIdentity()},
+		{"slerp end", BasisIdentity().Slerp(rotationY, 1), rotationY},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !test.got.IsEqualApprox(test.want) {
+				t.Errorf("got %v; want %v", test.got, test.want)
+			}
+		})
+	}
+}
+
+func TestBasisDecomposition(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		basis Basis
+		euler Vector3
+		quat  Quat
+		scale Vector3
+	}{
+		{"identity", BasisIdentity(), Vector3{}, QuatIdentity(), Vector3{1, 1, 1}},
+		{"rotation Y", NewBasisFromAxisAngle(Vector3{0, 1, 0}, pi/2), Vector3{0, pi / 2, 0}, Quat{0, 0.70710677, 0, 0.70710677}, Vector3{1, 1, 1}},
+		{"euler", eulerBasis, Vector3{0.3, 0.5, 0.7}, Quat{0.2198958, 0.1801459, 0.2937772, 0.9126271}, Vector3{1, 1, 1}},
+		{"scale", BasisIdentity().Scaled(Vector3{2, 3, 4}), Vector3{}, QuatIdentity(), Vector3{2, 3, 4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.basis.Euler(); !got.IsEqualApprox(test.euler) {
+				t.Errorf("Euler = %v; want %v", got, test.euler)
+			}
+			if got := test.basis.Orthonormalized().Quat(); !got.IsEqualApprox(test.quat) {
+				t.Errorf("Quat = %v; want %v", got, test.quat)
+			}
+			if got := test.basis.Scale(); !got.IsEqualApprox(test.scale) {
+				t.Errorf("Scale = %v; want %v", got, test.scale)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+// Package gdmath implements Godot's math value types in pure Go
+//
+// The types in this package mirror the memory layout of Godot's real_t
+// based builtins (Vector2, Vector3, Transform2D, Basis and Quat) so they can
+// be converted from and to the gdnative types with a single copy, but their
+// methods never cross cgo into the engine. They follow the formulas and
+// conventions of Godot 3.x, Euler angles use the YXZ order and a Basis is
+// stored as three rows.
+package gdmath
+
+import "math"
+
+const (
+	// CMPEpsilon is the tolerance used by Godot to compare real_t values
+	CMPEpsilon = 0.00001
+
+	// UnitEpsilon is the tolerance used by Godot to check if a vector or a
+	// quaternion is normalized
+	UnitEpsilon = 0.001
+
+	pi = math.Pi
+)
+
+// IsEqualApprox returns true if a and b are approximately equal, the
+// tolerance scales with the magnitude of the values
+func IsEqualApprox(a, b float32) bool {
+
+	if a == b {
+		return true
+	}
+
+	tolerance := float32(CMPEpsilon) * abs(a)
+	if tolerance < CMPEpsilon {
+		tolerance = CMPEpsilon
+	}
+
+	return abs(a-b) < tolerance
+}
+
+// IsZeroApprox returns true if the given value is approximately zero
+func IsZeroApprox(value float32) bool {
+	return abs(value) < CMPEpsilon
+}
+
+// Lerp linearly interpolates between from and to by the given weight
+func Lerp(from, to, weight float32) float32 {
+	return from + (to-from)*weight
+}
+
+// Stepify snaps the given value to the closest multiple of step, a zero
+// step returns the value unchanged
+func Stepify(value, step float32) float32 {
+
+	if step != 0 {
+		value = floor(value/step+0.5) * step
+	}
+
+	return value
+}
+
+// isEqualApproxWithTolerance compares a and b with a fixed tolerance
+func isEqualApproxWithTolerance(a, b, tolerance float32) bool {
+
+	if a == b {
+		return true
+	}
+
+	return abs(a-b) < tolerance
+}
+
+// sign returns -1 for negative values and 1 otherwise like Godot's SGN
+func sign(value float32) float32 {
+
+	if value < 0 {
+		return -1
+	}
+
+	return 1
+}
+
+func abs(value float32) float32 {
+	return float32(math.Abs(float64(value)))
+}
+
+func floor(value float32) float32 {
+	return float32(math.Floor(float64(value)))
+}
+
+func ceil(value float32) float32 {
+	return float32(math.Ceil(float64(value)))
+}
+
+func round(value float32) float32 {
+	return float32(math.Round(float64(value)))
+}
+
+func sqrt(value float32) float32 {
+	return float32(math.Sqrt(float64(value)))
+}
+
+func sin(value float32) float32 {
+	return float32(math.Sin(float64(value)))
+}
+
+func cos(value float32) float32 {
+	return float32(math.Cos(float64(value)))
+}
+
+func asin(value float32) float32 {
+	return float32(math.Asin(float64(value)))
+}
+
+func acos(value float32) float32 {
+	return float32(math.Acos(float64(value)))
+}
+
+func atan2(y, x float32) float32 {
+	return float32(math.Atan2(float64(y), float64(x)))
+}
+
+func clamp(value, min, max float32) float32 {
+
+	if value < min {
+		return min
+	}
+
+	if value > max {
+		return max
+	}
+
+	return value
+}
@@ -0,0 +1,191 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdmath
+
+// Quat is a quaternion used to represent 3D rotations
+type Quat struct {
+	X float32
+	Y float32
+	Z float32
+	W float32
+}
+
+// QuatIdentity returns back the identity Quat
+func QuatIdentity() Quat {
+	return Quat{0, 0, 0, 1}
+}
+
+// NewQuatFromAxisAngle creates a new Quat that rotates around the given
+// normalized axis by the given angle in radians, a zero axis gives back a
+// zero Quat
+func NewQuatFromAxisAngle(axis Vector3, angle float32) Quat {
+
+	length := axis.Length()
+	if length == 0 {
+		return Quat{}
+	}
+
+	s := sin(angle*0.5) / length
+	return Quat{axis.X * s, axis.Y * s, axis.Z * s, cos(angle * 0.5)}
+}
+
+// NewQuatFromEuler creates a new Quat from the given Euler angles in
+// radians using the YXZ convention
+func NewQuatFromEuler(euler Vector3) Quat {
+
+	cosA1, sinA1 := cos(euler.Y*0.5), sin(euler.Y*0.5)
+	cosA2, sinA2 := cos(euler.X*0.5), sin(euler.X*0.5)
+	cosA3, sinA3 := cos(euler.Z*0.5), sin(euler.Z*0.5)
+
+	return Quat{
+		sinA1*cosA2*sinA3 + cosA1*sinA2*cosA3,
+		sinA1*cosA2*cosA3 - cosA1*sinA2*sinA3,
+		-sinA1*sinA2*cosA3 + cosA1*cosA2*sinA3,
+		sinA1*sinA2*sinA3 + cosA1*cosA2*cosA3,
+	}
+}
+
+// Add returns back the component wise sum of both quaternions
+func (q Quat) Add(other Quat) Quat {
+	return Quat{q.X + other.X, q.Y + other.Y, q.Z + other.Z, q.W + other.W}
+}
+
+// Sub returns back the component wise difference of both quaternions
+func (q Quat) Sub(other Quat) Quat {
+	return Quat{q.X - other.X, q.Y - other.Y, q.Z - other.Z, q.W - other.W}
+}
+
+// Scale returns back the quaternion multiplied by the given scalar
+func (q Quat) Scale(scalar float32) Quat {
+	return Quat{q.X * scalar, q.Y * scalar, q.Z * scalar, q.W * scalar}
+}
+
+// Neg returns back the quaternion with all its components negated
+func (q Quat) Neg() Quat {
+	return Quat{-q.X, -q.Y, -q.Z, -q.W}
+}
+
+// Mul returns back the Hamilton product of both quaternions, that is the
+// composition of both rotations
+func (q Quat) Mul(other Quat) Quat {
+	return Quat{
+		q.W*other.X + q.X*other.W + q.Y*other.Z - q.Z*other.Y,
+		q.W*other.Y + q.Y*other.W + q.Z*other.X - q.X*other.Z,
+		q.W*other.Z + q.Z*other.W + q.X*other.Y - q.Y*other.X,
+		q.W*other.W - q.X*other.X - q.Y*other.Y - q.Z*other.Z,
+	}
+}
+
+// Dot returns back the dot product of both quaternions
+func (q Quat) Dot(other Quat) float32 {
+	return q.X*other.X + q.Y*other.Y + q.Z*other.Z + q.W*other.W
+}
+
+// Length returns back the length of the quaternion
+func (q Quat) Length() float32 {
+	return sqrt(q.LengthSquared())
+}
+
+// LengthSquared returns back the squared length of the quaternion
+func (q Quat) LengthSquared() float32 {
+	return q.Dot(q)
+}
+
+// Normalized returns back the quaternion scaled to unit length
+func (q Quat) Normalized() Quat {
+	return q.Scale(1 / q.Length())
+}
+
+// IsNormalized returns true if the quaternion is normalized
+func (q Quat) IsNormalized() bool {
+	return isEqualApproxWithTolerance(q.LengthSquared(), 1, UnitEpsilon)
+}
+
+// Inverse returns back the inverse rotation, the quaternion must be
+// normalized
+func (q Quat) Inverse() Quat {
+	return Quat{-q.X, -q.Y, -q.Z, q.W}
+}
+
+// Euler returns back the rotation as Euler angles in radians using the YXZ
+// convention
+func (q Quat) Euler() Vector3 {
+	return NewBasisFromQuat(q).Euler()
+}
+
+// Xform returns back the given vector rotated by the quaternion, the
+// quaternion must be normalized
+func (q Quat) Xform(v Vector3) Vector3 {
+
+	u := Vector3{q.X, q.Y, q.Z}
+	uv := u.Cross(v)
+	return v.Add(uv.Scale(q.W).Add(u.Cross(uv)).Scale(2))
+}
+
+// Slerp returns back the spherical linear interpolation between both
+// quaternions by the given weight taking the shortest path
+func (q Quat) Slerp(to Quat, weight float32) Quat {
+
+	cosom := q.Dot(to)
+	if cosom < 0 {
+		cosom = -cosom
+		to = to.Neg()
+	}
+
+	scale0, scale1 := 1-weight, weight
+	if 1-cosom > CMPEpsilon {
+		omega := acos(cosom)
+		sinom := sin(omega)
+		scale0 = sin((1-weight)*omega) / sinom
+		scale1 = sin(weight*omega) / sinom
+	}
+
+	return q.Scale(scale0).Add(to.Scale(scale1))
+}
+
+// Slerpni returns back the spherical linear interpolation between both
+// quaternions by the given weight without checking the rotation path
+func (q Quat) Slerpni(to Quat, weight float32) Quat {
+
+	dot := q.Dot(to)
+	if abs(dot) > 0.9999 {
+		return q
+	}
+
+	theta := acos(dot)
+	sinT := 1 / sin(theta)
+	newFactor := sin(weight*theta) * sinT
+	invFactor := sin((1-weight)*theta) * sinT
+
+	return q.Scale(invFactor).Add(to.Scale(newFactor))
+}
+
+// CubicSlerp returns back the spherical cubic interpolation between both
+// quaternions using preA and postB as handles
+func (q Quat) CubicSlerp(b, preA, postB Quat, weight float32) Quat {
+
+	t2 := (1 - weight) * weight * 2
+	sp := q.Slerp(b, weight)
+	sq := preA.Slerpni(postB, weight)
+
+	return sp.Slerpni(sq, t2)
+}
+
+// IsEqualApprox returns true if both quaternions are approximately equal
+func (q Quat) IsEqualApprox(other Quat) bool {
+	return IsEqualApprox(q.X, other.X) && IsEqualApprox(q.Y, other.Y) &&
+		IsEqualApprox(q.Z, other.Z) && IsEqualApprox(q.W, other.W)
+}
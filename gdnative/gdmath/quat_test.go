@@ -0,0 +1,124 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdmath
+
+import "testing"
+
+// expected values are the ones Godot 3.x gives back for the same operations
+
+var (
+	quatY90   = Quat{0, 0.70710677, 0, 0.70710677}
+	quatY45   = Quat{0, 0.38268343, 0, 0.9238795}
+	eulerQuat = Quat{0.2198958, 0.1801459, 0.2937772, 0.9126271}
+)
+
+func TestQuatConstructors(t *testing.T) {
+
+	tests := []struct {
+		name string
+		got  Quat
+		want Quat
+	}{
+		{"axis angle", NewQuatFromAxisAngle(Vector3{0, 1, 0}, pi/2), quatY90},
+		{"axis angle X", NewQuatFromAxisAngle(Vector3{1, 0, 0}, pi), Quat{1, 0, 0, 0}},
+		{"zero axis", NewQuatFromAxisAngle(Vector3{}, pi), Quat{}},
+		{"euler", NewQuatFromEuler(Vector3{0.3, 0.5, 0.7}), eulerQuat},
+		{"basis", NewBasisFromEuler(Vector3{0.3, 0.5, 0.7}).Quat(), eulerQuat},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !test.got.IsEqualApprox(test.want) {
+				t.Errorf("got %v; want %v", test.got, test.want)
+			}
+		})
+	}
+}
+
+func TestQuatXform(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		quat   Quat
+		vector Vector3
+		want   Vector3
+	}{
+		{"identity", QuatIdentity(), Vector3{1, 2, 3}, Vector3{1, 2, 3}},
+		{"rotation Y", quatY90, Vector3{1, 0, 0}, Vector3{0, 0, -1}},
+		{"rotation Z", NewQuatFromAxisAngle(Vector3{0, 0, 1}, pi/2), Vector3{1, 0, 0}, Vector3{0, 1, 0}},
+		{"euler", eulerQuat, Vector3{1, 2, 3}, Vector3{1.2225402, 1.1902473, 3.329971}},
+		{"inverse", eulerQuat.Inverse(), Vector3{1, 2, 3}, Vector3{1.3945404, 2.5260054, 2.3821322}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.quat.Xform(test.vector); !got.IsEqualApprox(test.want) {
+				t.Errorf("Xform = %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestQuatOperations(t *testing.T) {
+
+	tests := []struct {
+		name string
+		got  Quat
+		want Quat
+	}{
+		{"mul", quatY45.Mul(quatY45), quatY90},
+		{"mul identity", eulerQuat.Mul(QuatIdentity()), eulerQuat},
+		{"inverse", quatY90.Inverse(), NewQuatFromAxisAngle(Vector3{0, 1, 0}, -pi/2)},
+		{"mul inverse", eulerQuat.Mul(eulerQuat.Inverse()), QuatIdentity()},
+		{"normalized", Quat{0, 2, 0, 2}.Normalized(), quatY90},
+		{"slerp", QuatIdentity().Slerp(quatY90, 0.5), quatY45},
+		{"slerp start", QuatIdentity().Slerp(quatY90, 0), QuatIdentity()},
+		{"slerp end", QuatIdentity().Slerp(quatY90, 1), quatY90},
+		{"slerp shortest path", QuatIdentity().Slerp(quatY90.Neg(), 0.5), quatY45},
+		{"slerpni", QuatIdentity().Slerpni(quatY90, 0.5), quatY45},
+		{"slerpni longest path", QuatIdentity().Slerpni(quatY90.Neg(), 0.5), Quat{0, -0.9238795, 0, 0.38268343}},
+		{"cubic slerp", QuatIdentity().CubicSlerp(quatY90, QuatIdentity(), quatY90, 0.5), quatY45},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !test.got.IsEqualApprox(test.want) {
+				t.Errorf("got %v; want %v", test.got, test.want)
+			}
+		})
+	}
+}
+
+func TestQuatEuler(t *testing.T) {
+
+	tests := []struct {
+		name string
+		quat Quat
+		want Vector3
+	}{
+		{"identity", QuatIdentity(), Vector3{}},
+		{"rotation Y", quatY90, Vector3{0, pi / 2, 0}},
+		{"euler", eulerQuat, Vector3{0.3, 0.5, 0.7}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.quat.Euler(); !got.IsEqualApprox(test.want) {
+				t.Errorf("Euler = %v; want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdmath
+
+// Transform2D is a 2D affine transformation, its elements are the X axis,
+// the Y axis and the origin
+type Transform2D struct {
+	Elements [3]Vector2
+}
+
+// Transform2DIdentity returns back the identity Transform2D
+func Transform2DIdentity() Transform2D {
+	return Transform2D{Elements: [3]Vector2{{1, 0}, {0, 1}, {0, 0}}}
+}
+
+// NewTransform2D creates a new Transform2D with the given rotation in
+// radians and position
+func NewTransform2D(rotation float32, position Vector2) Transform2D {
+
+	cr, sr := cos(rotation), sin(rotation)
+	return Transform2D{Elements: [3]Vector2{{cr, sr}, {-sr, cr}, position}}
+}
+
+// NewTransform2DAxisOrigin creates a new Transform2D from the given axes
+// and origin
+func NewTransform2DAxisOrigin(xAxis, yAxis, origin Vector2) Transform2D {
+	return Transform2D{Elements: [3]Vector2{xAxis, yAxis, origin}}
+}
+
+// Origin returns back the translation of the transform
+func (t Transform2D) Origin() Vector2 {
+	return t.Elements[2]
+}
+
+// tdotx and tdoty return back the dot product of the given vector with the
+// rows of the basis
+func (t Transform2D) tdotx(v Vector2) float32 {
+	return t.Elements[0].X*v.X + t.Elements[1].X*v.Y
+}
+
+func (t Transform2D) tdoty(v Vector2) float32 {
+	return t.Elements[0].Y*v.X + t.Elements[1].Y*v.Y
+}
+
+// BasisDeterminant returns back the determinant of the basis
+func (t Transform2D) BasisDeterminant() float32 {
+	return t.Elements[0].X*t.Elements[1].Y - t.Elements[0].Y*t.Elements[1].X
+}
+
+// BasisXform returns back the given vector transformed by the basis only
+func (t Transform2D) BasisXform(v Vector2) Vector2 {
+	return Vector2{t.tdotx(v), t.tdoty(v)}
+}
+
+// BasisXformInv returns back the given vector transformed by the transposed
+// basis only
+func (t Transform2D) BasisXformInv(v Vector2) Vector2 {
+	return Vector2{t.Elements[0].Dot(v), t.Elements[1].Dot(v)}
+}
+
+// Xform returns back the given vector transformed by the transform
+func (t Transform2D) Xform(v Vector2) Vector2 {
+	return t.BasisXform(v).Add(t.Elements[2])
+}
+
+// XformInv returns back the given vector transformed by the inverse of the
+// transform, the transform must be orthonormal
+func (t Transform2D) XformInv(v Vector2) Vector2 {
+	return t.BasisXformInv(v.Sub(t.Elements[2]))
+}
+
+// Mul returns back the composition of both transforms
+func (t Transform2D) Mul(other Transform2D) Transform2D {
+	return Transform2D{Elements: [3]Vector2{
+		t.BasisXform(other.Elements[0]),
+		t.BasisXform(other.Elements[1]),
+		t.Xform(other.Elements[2]),
+	}}
+}
+
+// Inverse returns back the inverse of the transform, the transform must be
+// orthonormal, use AffineInverse otherwise
+func (t Transform2D) Inverse() Transform2D {
+
+	t.Elements[0].Y, t.Elements[1].X = t.Elements[1].X, t.Elements[0].Y
+	t.Elements[2] = t.BasisXform(t.Elements[2].Neg())
+
+	return t
+}
+
+// AffineInverse returns back the inverse of the transform, like Godot a
+// transform with a singular basis is returned unchanged
+func (t Transform2D) AffineInverse() Transform2D {
+
+	det := t.BasisDeterminant()
+	if det == 0 {
+		return t
+	}
+
+	idet := 1 / det
+	t.Elements[0].X, t.Elements[1].Y = t.Elements[1].Y, t.Elements[0].X
+	t.Elements[0] = t.Elements[0].Mul(Vector2{idet, -idet})
+	t.Elements[1] = t.Elements[1].Mul(Vector2{-idet, idet})
+	t.Elements[2] = t.BasisXform(t.Elements[2].Neg())
+
+	return t
+}
+
+// Rotation returns back the rotation of the transform in radians
+func (t Transform2D) Rotation() float32 {
+	return atan2(t.Elements[0].Y, t.Elements[0].X)
+}
+
+// Scale returns back the scale of the transform, the Y axis scale is
+// negated if the transform flips the handedness of the space
+func (t Transform2D) Scale() Vector2 {
+	return Vector2{t.Elements[0].Length(), sign(t.BasisDeterminant()) * t.Elements[1].Length()}
+}
+
+// Rotated returns back the transform rotated by the given angle in radians
+func (t Transform2D) Rotated(phi float32) Transform2D {
+	return NewTransform2D(phi, Vector2{}).Mul(t)
+}
+
+// Scaled returns back the transform scaled by the given factors, the
+// origin is scaled too
+func (t Transform2D) Scaled(scale Vector2) Transform2D {
+
+	t = t.scaleBasis(scale)
+	t.Elements[2] = t.Elements[2].Mul(scale)
+
+	return t
+}
+
+// scaleBasis returns back the transform with its basis scaled by the given
+// factors
+func (t Transform2D) scaleBasis(scale Vector2) Transform2D {
+
+	t.Elements[0] = t.Elements[0].Mul(scale)
+	t.Elements[1] = t.Elements[1].Mul(scale)
+
+	return t
+}
+
+// Translated returns back the transform translated by the given offset
+// relative to its basis
+func (t Transform2D) Translated(offset Vector2) Transform2D {
+
+	t.Elements[2] = t.Elements[2].Add(t.BasisXform(offset))
+	return t
+}
+
+// Orthonormalized returns back the transform with its axes orthogonal and
+// normalized using Gram-Schmidt
+func (t Transform2D) Orthonormalized() Transform2D {
+
+	x := t.Elements[0].Normalized()
+	y := t.Elements[1]
+	y = y.Sub(x.Scale(x.Dot(y))).Normalized()
+
+	t.Elements[0], t.Elements[1] = x, y
+	return t
+}
+
+// InterpolateWith returns back the interpolation between both transforms
+// by the given weight, rotations are interpolated spherically and origins
+// and scales linearly
+func (t Transform2D) InterpolateWith(to Transform2D, weight float32) Transform2D {
+
+	r1, r2 := t.Rotation(), to.Rotation()
+	v1 := Vector2{cos(r1), sin(r1)}
+	v2 := Vector2{cos(r2), sin(r2)}
+
+	var v Vector2
+	dot := clamp(v1.Dot(v2), -1, 1)
+	if dot > 0.9995 {
+		// interpolate linearly to avoid numerical precision issues
+		v = v1.LinearInterpolate(v2, weight).Normalized()
+	} else {
+		angle := weight * acos(dot)
+		v3 := v2.Sub(v1.Scale(dot)).Normalized()
+		v = v1.Scale(cos(angle)).Add(v3.Scale(sin(angle)))
+	}
+
+	result := NewTransform2D(atan2(v.Y, v.X), t.Origin().LinearInterpolate(to.Origin(), weight))
+	return result.scaleBasis(t.Scale().LinearInterpolate(to.Scale(), weight))
+}
+
+// IsEqualApprox returns true if both transforms are approximately equal
+func (t Transform2D) IsEqualApprox(other Transform2D) bool {
+	return t.Elements[0].IsEqualApprox(other.Elements[0]) &&
+		t.Elements[1].IsEqualApprox(other.Elements[1]) &&
+		t.Elements[2].IsEqualApprox(other.Elements[2])
+}
@@ -0,0 +1,150 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdmath
+
+import "testing"
+
+// expected values are the ones Godot 3.x gives back for the same operations
+
+func TestTransform2DXform(t *testing.T) {
+
+	transform := NewTransform2D(pi/2, Vector2{10, 0})
+	tests := []struct {
+		name string
+		got  Vector2
+		want Vector2
+	}{
+		{"xform", transform.Xform(Vector2{1, 0}), Vector2{10, 1}},
+		{"xform inv", transform.XformInv(Vector2{10, 1}), Vector2{1, 0}},
+		{"basis xform", transform.BasisXform(Vector2{1, 0}), Vector2{0, 1}},
+		{"basis xform inv", transform.BasisXformInv(Vector2{0, 1}), Vector2{1, 0}},
+		{"identity", Transform2DIdentity().Xform(Vector2{3, 4}), Vector2{3, 4}},
+		{"scaled", Transform2DIdentity().Scaled(Vector2{2, 3}).Xform(Vector2{1, 1}), Vector2{2, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !test.got.IsEqualApprox(test.want) {
+				t.Errorf("got %v; want %v", test.got, test.want)
+			}
+		})
+	}
+}
+
+func TestTransform2DInverse(t *testing.T) {
+
+	tests := []struct {
+		name string
+		got  Transform2D
+		want Transform2D
+	}{
+		{
+			"inverse",
+			NewTransform2D(pi/2, Vector2{10, 0}).Inverse(),
+			NewTransform2DAxisOrigin(Vector2{0, -1}, Vector2{1, 0}, Vector2{0, 10}),
+		},
+		{
+			"affine inverse of a rotation",
+			NewTransform2D(pi/2, Vector2{10, 0}).AffineInverse(),
+			NewTransform2DAxisOrigin(Vector2{0, -1}, Vector2{1, 0}, Vector2{0, 10}),
+		},
+		{
+			"affine inverse of a scale",
+			NewTransform2DAxisOrigin(Vector2{2, 0}, Vector2{0, 4}, Vector2{2, 4}).AffineInverse(),
+			NewTransform2DAxisOrigin(Vector2{0.5, 0}, Vector2{0, 0.25}, Vector2{-1, -1}),
+		},
+		{
+			"affine inverse of a singular basis",
+			NewTransform2DAxisOrigin(Vector2{1, 2}, Vector2{2, 4}, Vector2{1, 1}).AffineInverse(),
+			NewTransform2DAxisOrigin(Vector2{1, 2}, Vector2{2, 4}, Vector2{1, 1}),
+		},
+		{
+			"mul affine inverse",
+			NewTransform2D(0.5, Vector2{3, 4}).Scaled(Vector2{2, 3}).Mul(NewTransform2D(0.5, Vector2{3, 4}).Scaled(Vector2{2, 3}).AffineInverse()),
+			Transform2DIdentity(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !test.got.IsEqualApprox(test.want) {
+				t.Errorf("got %v; want %v", test.got, test.want)
+			}
+		})
+	}
+}
+
+func TestTransform2DOperations(t *testing.T) {
+
+	transform := NewTransform2D(pi/2, Vector2{10, 0})
+	tests := []struct {
+		name string
+		got  Transform2D
+		want Transform2D
+	}{
+		{"mul", transform.Mul(transform), NewTransform2D(pi, Vector2{10, 10})},
+		{"rotated", Transform2DIdentity().Translated(Vector2{1, 0}).Rotated(pi / 2), NewTransform2D(pi/2, Vector2{0, 1})},
+		{"scaled", transform.Scaled(Vector2{2, 3}), NewTransform2DAxisOrigin(Vector2{0, 3}, Vector2{-2, 0}, Vector2{20, 0})},
+		{"translated", transform.Translated(Vector2{1, 0}), NewTransform2D(pi/2, Vector2{10, 1})},
+		{
+			"orthonormalized",
+			NewTransform2DAxisOrigin(Vector2{2, 0}, Vector2{1, 3}, Vector2{5, 5}).Orthonormalized(),
+			NewTransform2DAxisOrigin(Vector2{1, 0}, Vector2{0, 1}, Vector2{5, 5}),
+		},
+		{"interpolate", Transform2DIdentity().InterpolateWith(transform, 0.5), NewTransform2D(pi/4, Vector2{5, 0})},
+		{
+			"interpolate scale",
+			Transform2DIdentity().InterpolateWith(Transform2DIdentity().Scaled(Vector2{3, 3}), 0.5),
+			Transform2DIdentity().Scaled(Vector2{2, 2}),
+		},
+		{"interpolate start", Transform2DIdentity().InterpolateWith(transform, 0), Transform2DIdentity()},
+		{"interpolate end", Transform2DIdentity().InterpolateWith(transform, 1), transform},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !test.got.IsEqualApprox(test.want) {
+				t.Errorf("got %v; want %v", test.got, test.want)
+			}
+		})
+	}
+}
+
+func TestTransform2DDecomposition(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		transform Transform2D
+		rotation  float32
+		scale     Vector2
+	}{
+		{"identity", Transform2DIdentity(), 0, Vector2{1, 1}},
+		{"rotation", NewTransform2D(pi/2, Vector2{10, 0}), pi / 2, Vector2{1, 1}},
+		{"rotation and scale", Transform2DIdentity().Scaled(Vector2{2, 3}).Rotated(0.5), 0.5, Vector2{2, 3}},
+		{"flipped", NewTransform2DAxisOrigin(Vector2{1, 0}, Vector2{0, -1}, Vector2{}), 0, Vector2{1, -1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.transform.Rotation(); !IsEqualApprox(got, test.rotation) {
+				t.Errorf("Rotation = %v; want %v", got, test.rotation)
+			}
+			if got := test.transform.Scale(); !got.IsEqualApprox(test.scale) {
+				t.Errorf("Scale = %v; want %v", got, test.scale)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdmath
+
+// Vector2 is a 2D vector with real_t components
+type Vector2 struct {
+	X float32
+	Y float32
+}
+
+// Add returns back the component wise sum of both vectors
+func (v Vector2) Add(other Vector2) Vector2 {
+	return Vector2{v.X + other.X, v.Y + other.Y}
+}
+
+// Sub returns back the component wise difference of both vectors
+func (v Vector2) Sub(other Vector2) Vector2 {
+	return Vector2{v.X - other.X, v.Y - other.Y}
+}
+
+// Mul returns back the component wise product of both vectors
+func (v Vector2) Mul(other Vector2) Vector2 {
+	return Vector2{v.X * other.X, v.Y * other.Y}
+}
+
+// Div returns back the component wise quotient of both vectors
+func (v Vector2) Div(other Vector2) Vector2 {
+	return Vector2{v.X / other.X, v.Y / other.Y}
+}
+
+// Scale returns back the vector multiplied by the given scalar
+func (v Vector2) Scale(scalar float32) Vector2 {
+	return Vector2{v.X * scalar, v.Y * scalar}
+}
+
+// Neg returns back the vector with all its components negated
+func (v Vector2) Neg() Vector2 {
+	return Vector2{-v.X, -v.Y}
+}
+
+// Dot returns back the dot product of both vectors
+func (v Vector2) Dot(other Vector2) float32 {
+	return v.X*other.X + v.Y*other.Y
+}
+
+// Cross returns back the 2D cross product (the Z component of the 3D cross
+// product) of both vectors
+func (v Vector2) Cross(other Vector2) float32 {
+	return v.X*other.Y - v.Y*other.X
+}
+
+// Length returns back the length of the vector
+func (v Vector2) Length() float32 {
+	return sqrt(v.X*v.X + v.Y*v.Y)
+}
+
+// LengthSquared returns back the squared length of the vector
+func (v Vector2) LengthSquared() float32 {
+	return v.X*v.X + v.Y*v.Y
+}
+
+// Normalized returns back the vector scaled to unit length, a zero vector
+// is returned unchanged
+func (v Vector2) Normalized() Vector2 {
+
+	length := v.X*v.X + v.Y*v.Y
+	if length != 0 {
+		length = sqrt(length)
+		v.X /= length
+		v.Y /= length
+	}
+
+	return v
+}
+
+// IsNormalized returns true if the vector is normalized
+func (v Vector2) IsNormalized() bool {
+	return isEqualApproxWithTolerance(v.LengthSquared(), 1, UnitEpsilon)
+}
+
+// Angle returns back the angle of the vector with the positive X axis in
+// radians
+func (v Vector2) Angle() float32 {
+	return atan2(v.Y, v.X)
+}
+
+// AngleTo returns back the signed angle to the given vector in radians
+func (v Vector2) AngleTo(to Vector2) float32 {
+	return atan2(v.Cross(to), v.Dot(to))
+}
+
+// AngleToPoint returns back the angle of the line from the given point to
+// this one in radians
+func (v Vector2) AngleToPoint(point Vector2) float32 {
+	return atan2(v.Y-point.Y, v.X-point.X)
+}
+
+// DirectionTo returns back the normalized vector pointing to the given one
+func (v Vector2) DirectionTo(to Vector2) Vector2 {
+	return to.Sub(v).Normalized()
+}
+
+// DistanceTo returns back the distance to the given vector
+func (v Vector2) DistanceTo(to Vector2) float32 {
+	return to.Sub(v).Length()
+}
+
+// DistanceSquaredTo returns back the squared distance to the given vector
+func (v Vector2) DistanceSquaredTo(to Vector2) float32 {
+	return to.Sub(v).LengthSquared()
+}
+
+// Aspect returns back the ratio of X to Y
+func (v Vector2) Aspect() float32 {
+	return v.X / v.Y
+}
+
+// Abs returns back the vector with the absolute value of its components
+func (v Vector2) Abs() Vector2 {
+	return Vector2{abs(v.X), abs(v.Y)}
+}
+
+// Floor returns back the vector with its components rounded down
+func (v Vector2) Floor() Vector2 {
+	return Vector2{floor(v.X), floor(v.Y)}
+}
+
+// Ceil returns back the vector with its components rounded up
+func (v Vector2) Ceil() Vector2 {
+	return Vector2{ceil(v.X), ceil(v.Y)}
+}
+
+// Round returns back the vector with its components rounded to the nearest
+// integer, halfway cases are rounded away from zero
+func (v Vector2) Round() Vector2 {
+	return Vector2{round(v.X), round(v.Y)}
+}
+
+// Sign returns back the sign of each component, like Godot 3 zero is
+// considered positive
+func (v Vector2) Sign() Vector2 {
+	return Vector2{sign(v.X), sign(v.Y)}
+}
+
+// Snapped returns back the vector with its components snapped to the
+// closest multiple of the components of the given step
+func (v Vector2) Snapped(step Vector2) Vector2 {
+	return Vector2{Stepify(v.X, step.X), Stepify(v.Y, step.Y)}
+}
+
+// Clamped returns back the vector with its length limited to the given one
+func (v Vector2) Clamped(length float32) Vector2 {
+
+	current := v.Length()
+	if current > 0 && length < current {
+		v = v.Scale(length / current)
+	}
+
+	return v
+}
+
+// Rotated returns back the vector rotated by the given angle in radians
+func (v Vector2) Rotated(phi float32) Vector2 {
+
+	sine, cosine := sin(phi), cos(phi)
+	return Vector2{v.X*cosine - v.Y*sine, v.X*sine + v.Y*cosine}
+}
+
+// Tangent returns back the vector rotated 90 degrees clockwise
+func (v Vector2) Tangent() Vector2 {
+	return Vector2{v.Y, -v.X}
+}
+
+// Project returns back the projection of the vector onto the given one
+func (v Vector2) Project(onto Vector2) Vector2 {
+	return onto.Scale(v.Dot(onto) / onto.LengthSquared())
+}
+
+// Reflect returns back the vector reflected from the plane defined by the
+// given normal
+func (v Vector2) Reflect(normal Vector2) Vector2 {
+	return normal.Scale(2 * v.Dot(normal)).Sub(v)
+}
+
+// Bounce returns back the vector bounced off the plane defined by the given
+// normal
+func (v Vector2) Bounce(normal Vector2) Vector2 {
+	return v.Reflect(normal).Neg()
+}
+
+// Slide returns back the component of the vector along the plane defined
+// by the given normal
+func (v Vector2) Slide(normal Vector2) Vector2 {
+	return v.Sub(normal.Scale(v.Dot(normal)))
+}
+
+// LinearInterpolate returns back the linear interpolation between this
+// vector and the given one by the given weight
+func (v Vector2) LinearInterpolate(to Vector2, weight float32) Vector2 {
+	return Vector2{v.X + weight*(to.X-v.X), v.Y + weight*(to.Y-v.Y)}
+}
+
+// Slerp returns back the spherical linear interpolation between this vector
+// and the given one by the given weight, both vectors must be normalized
+func (v Vector2) Slerp(to Vector2, weight float32) Vector2 {
+	return v.Rotated(v.AngleTo(to) * weight)
+}
+
+// CubicInterpolate returns back the cubic interpolation between this vector
+// and b using preA and postB as handles
+func (v Vector2) CubicInterpolate(b, preA, postB Vector2, weight float32) Vector2 {
+
+	t2 := weight * weight
+	t3 := t2 * weight
+
+	return v.Scale(2).
+		Add(preA.Neg().Add(b).Scale(weight)).
+		Add(preA.Scale(2).Sub(v.Scale(5)).Add(b.Scale(4)).Sub(postB).Scale(t2)).
+		Add(preA.Neg().Add(v.Scale(3)).Sub(b.Scale(3)).Add(postB).Scale(t3)).
+		Scale(0.5)
+}
+
+// MoveToward returns back the vector moved toward the given one by at most
+// delta, the target is returned if it is closer than delta
+func (v Vector2) MoveToward(to Vector2, delta float32) Vector2 {
+
+	difference := to.Sub(v)
+	length := difference.Length()
+	if length <= delta || length < CMPEpsilon {
+		return to
+	}
+
+	return v.Add(difference.Scale(delta / length))
+}
+
+// IsEqualApprox returns true if both vectors are approximately equal
+func (v Vector2) IsEqualApprox(other Vector2) bool {
+	return IsEqualApprox(v.X, other.X) && IsEqualApprox(v.Y, other.Y)
+}
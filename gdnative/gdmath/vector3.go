@@ -0,0 +1,303 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdmath
+
+// Vector3 is a 3D vector with real_t components
+type Vector3 struct {
+	X float32
+	Y float32
+	Z float32
+}
+
+// Vector3 axis indexes as returned by MaxAxis and MinAxis
+const (
+	Vector3AxisX = iota
+	Vector3AxisY
+	Vector3AxisZ
+)
+
+// Axis returns back the component at the given axis index
+func (v Vector3) Axis(axis int) float32 {
+
+	switch axis {
+	case Vector3AxisX:
+		return v.X
+	case Vector3AxisY:
+		return v.Y
+	default:
+		return v.Z
+	}
+}
+
+// Add returns back the component wise sum of both vectors
+func (v Vector3) Add(other Vector3) Vector3 {
+	return Vector3{v.X + other.X, v.Y + other.Y, v.Z + other.Z}
+}
+
+// Sub returns back the component wise difference of both vectors
+func (v Vector3) Sub(other Vector3) Vector3 {
+	return Vector3{v.X - other.X, v.Y - other.Y, v.Z - other.Z}
+}
+
+// Mul returns back the component wise product of both vectors
+func (v Vector3) Mul(other Vector3) Vector3 {
+	return Vector3{v.X * other.X, v.Y * other.Y, v.Z * other.Z}
+}
+
+// Div returns back the component wise quotient of both vectors
+func (v Vector3) Div(other Vector3) Vector3 {
+	return Vector3{v.X / other.X, v.Y / other.Y, v.Z / other.Z}
+}
+
+// Scale returns back the vector multiplied by the given scalar
+func (v Vector3) Scale(scalar float32) Vector3 {
+	return Vector3{v.X * scalar, v.Y * scalar, v.Z * scalar}
+}
+
+// Neg returns back the vector with all its components negated
+func (v Vector3) Neg() Vector3 {
+	return Vector3{-v.X, -v.Y, -v.Z}
+}
+
+// Inverse returns back the vector with the reciprocal of its components
+func (v Vector3) Inverse() Vector3 {
+	return Vector3{1 / v.X, 1 / v.Y, 1 / v.Z}
+}
+
+// Dot returns back the dot product of both vectors
+func (v Vector3) Dot(other Vector3) float32 {
+	return v.X*other.X + v.Y*other.Y + v.Z*other.Z
+}
+
+// Cross returns back the cross product of both vectors
+func (v Vector3) Cross(other Vector3) Vector3 {
+	return Vector3{
+		v.Y*other.Z - v.Z*other.Y,
+		v.Z*other.X - v.X*other.Z,
+		v.X*other.Y - v.Y*other.X,
+	}
+}
+
+// Outer returns back the outer product of both vectors
+func (v Vector3) Outer(other Vector3) Basis {
+	return Basis{Elements: [3]Vector3{
+		other.Scale(v.X),
+		other.Scale(v.Y),
+		other.Scale(v.Z),
+	}}
+}
+
+// ToDiagonalMatrix returns back a Basis with the vector as its diagonal
+func (v Vector3) ToDiagonalMatrix() Basis {
+	return Basis{Elements: [3]Vector3{
+		{v.X, 0, 0},
+		{0, v.Y, 0},
+		{0, 0, v.Z},
+	}}
+}
+
+// Length returns back the length of the vector
+func (v Vector3) Length() float32 {
+	return sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+}
+
+// LengthSquared returns back the squared length of the vector
+func (v Vector3) LengthSquared() float32 {
+	return v.X*v.X + v.Y*v.Y + v.Z*v.Z
+}
+
+// Normalized returns back the vector scaled to unit length, a zero vector
+// is returned unchanged
+func (v Vector3) Normalized() Vector3 {
+
+	length := v.Length()
+	if length == 0 {
+		return Vector3{}
+	}
+
+	return Vector3{v.X / length, v.Y / length, v.Z / length}
+}
+
+// IsNormalized returns true if the vector is normalized
+func (v Vector3) IsNormalized() bool {
+	return isEqualApproxWithTolerance(v.LengthSquared(), 1, UnitEpsilon)
+}
+
+// AngleTo returns back the unsigned angle to the given vector in radians
+func (v Vector3) AngleTo(to Vector3) float32 {
+	return atan2(v.Cross(to).Length(), v.Dot(to))
+}
+
+// DirectionTo returns back the normalized vector pointing to the given one
+func (v Vector3) DirectionTo(to Vector3) Vector3 {
+	return to.Sub(v).Normalized()
+}
+
+// DistanceTo returns back the distance to the given vector
+func (v Vector3) DistanceTo(to Vector3) float32 {
+	return to.Sub(v).Length()
+}
+
+// DistanceSquaredTo returns back the squared distance to the given vector
+func (v Vector3) DistanceSquaredTo(to Vector3) float32 {
+	return to.Sub(v).LengthSquared()
+}
+
+// MaxAxis returns back the index of the largest component
+func (v Vector3) MaxAxis() int {
+
+	if v.X < v.Y {
+		if v.Y < v.Z {
+			return Vector3AxisZ
+		}
+		return Vector3AxisY
+	}
+
+	if v.X < v.Z {
+		return Vector3AxisZ
+	}
+
+	return Vector3AxisX
+}
+
+// MinAxis returns back the index of the smallest component
+func (v Vector3) MinAxis() int {
+
+	if v.X < v.Y {
+		if v.X < v.Z {
+			return Vector3AxisX
+		}
+		return Vector3AxisZ
+	}
+
+	if v.Y < v.Z {
+		return Vector3AxisY
+	}
+
+	return Vector3AxisZ
+}
+
+// Abs returns back the vector with the absolute value of its components
+func (v Vector3) Abs() Vector3 {
+	return Vector3{abs(v.X), abs(v.Y), abs(v.Z)}
+}
+
+// Floor returns back the vector with its components rounded down
+func (v Vector3) Floor() Vector3 {
+	return Vector3{floor(v.X), floor(v.Y), floor(v.Z)}
+}
+
+// Ceil returns back the vector with its components rounded up
+func (v Vector3) Ceil() Vector3 {
+	return Vector3{ceil(v.X), ceil(v.Y), ceil(v.Z)}
+}
+
+// Round returns back the vector with its components rounded to the nearest
+// integer, halfway cases are rounded away from zero
+func (v Vector3) Round() Vector3 {
+	return Vector3{round(v.X), round(v.Y), round(v.Z)}
+}
+
+// Sign returns back the sign of each component, like Godot 3 zero is
+// considered positive
+func (v Vector3) Sign() Vector3 {
+	return Vector3{sign(v.X), sign(v.Y), sign(v.Z)}
+}
+
+// Snapped returns back the vector with its components snapped to the
+// closest multiple of the components of the given step
+func (v Vector3) Snapped(step Vector3) Vector3 {
+	return Vector3{Stepify(v.X, step.X), Stepify(v.Y, step.Y), Stepify(v.Z, step.Z)}
+}
+
+// Rotated returns back the vector rotated around the given normalized axis
+// by the given angle in radians
+func (v Vector3) Rotated(axis Vector3, phi float32) Vector3 {
+	return NewBasisFromAxisAngle(axis, phi).Xform(v)
+}
+
+// Project returns back the projection of the vector onto the given one
+func (v Vector3) Project(onto Vector3) Vector3 {
+	return onto.Scale(v.Dot(onto) / onto.LengthSquared())
+}
+
+// Reflect returns back the vector reflected from the plane defined by the
+// given normal
+func (v Vector3) Reflect(normal Vector3) Vector3 {
+	return normal.Scale(2 * v.Dot(normal)).Sub(v)
+}
+
+// Bounce returns back the vector bounced off the plane defined by the given
+// normal
+func (v Vector3) Bounce(normal Vector3) Vector3 {
+	return v.Reflect(normal).Neg()
+}
+
+// Slide returns back the component of the vector along the plane defined
+// by the given normal
+func (v Vector3) Slide(normal Vector3) Vector3 {
+	return v.Sub(normal.Scale(v.Dot(normal)))
+}
+
+// LinearInterpolate returns back the linear interpolation between this
+// vector and the given one by the given weight
+func (v Vector3) LinearInterpolate(to Vector3, weight float32) Vector3 {
+	return Vector3{
+		v.X + weight*(to.X-v.X),
+		v.Y + weight*(to.Y-v.Y),
+		v.Z + weight*(to.Z-v.Z),
+	}
+}
+
+// Slerp returns back the spherical linear interpolation between this vector
+// and the given one by the given weight, both vectors must be normalized
+func (v Vector3) Slerp(to Vector3, weight float32) Vector3 {
+
+	theta := v.AngleTo(to)
+	return v.Rotated(v.Cross(to).Normalized(), theta*weight)
+}
+
+// CubicInterpolate returns back the cubic interpolation between this vector
+// and b using preA and postB as handles
+func (v Vector3) CubicInterpolate(b, preA, postB Vector3, weight float32) Vector3 {
+
+	t2 := weight * weight
+	t3 := t2 * weight
+
+	return v.Scale(2).
+		Add(preA.Neg().Add(b).Scale(weight)).
+		Add(preA.Scale(2).Sub(v.Scale(5)).Add(b.Scale(4)).Sub(postB).Scale(t2)).
+		Add(preA.Neg().Add(v.Scale(3)).Sub(b.Scale(3)).Add(postB).Scale(t3)).
+		Scale(0.5)
+}
+
+// MoveToward returns back the vector moved toward the given one by at most
+// delta, the target is returned if it is closer than delta
+func (v Vector3) MoveToward(to Vector3, delta float32) Vector3 {
+
+	difference := to.Sub(v)
+	length := difference.Length()
+	if length <= delta || length < CMPEpsilon {
+		return to
+	}
+
+	return v.Add(difference.Scale(delta / length))
+}
+
+// IsEqualApprox returns true if both vectors are approximately equal
+func (v Vector3) IsEqualApprox(other Vector3) bool {
+	return IsEqualApprox(v.X, other.X) && IsEqualApprox(v.Y, other.Y) && IsEqualApprox(v.Z, other.Z)
+}
@@ -24,13 +24,16 @@ package gdnative
 #include <gdnative/gdnative.h>
 #include <gdnative_api_struct.gen.h>
 #include "gdnative.gen.h"
+#include "nativescript.h"
 #include "util.h"
+#include "variant.h"
 */
 import "C"
 
 import (
 	"fmt"
 	"log"
+	"sync/atomic"
 	"unsafe"
 
 	wchar "github.com/vitaminwater/cgo.wchar"
@@ -50,13 +53,23 @@ var GDNative = new(gdNative)
 
 // gdNative is a structure that wraps the GDNativeAPI.
 type gdNative struct {
-	api         *C.godot_gdnative_core_api_struct
-	initialized bool
+	api *C.godot_gdnative_core_api_struct
+
+	// api11 is the core 1.1 API, it is nil if the running Godot version
+	// does not provide it.
+	api11 *C.godot_gdnative_core_1_1_api_struct
+
+	// library is the GDNativeLibrary resource this library was loaded from
+	library *C.godot_object
+
+	// initialized is read from any goroutine (loggers, stdio forwarding...),
+	// it is set after api so a goroutine that sees it set sees the API too
+	initialized atomic.Bool
 }
 
 // IsInitialized will return true if `godot_gdnative_init` was called by Godot.
 func (g *gdNative) IsInitialized() bool {
-	return g.initialized
+	return g.initialized.Load()
 }
 
 // CheckInit will check to see if GDNative has initialized. If it is not, it will
@@ -75,7 +88,10 @@ func godot_gdnative_init(options *C.godot_gdnative_init_options) {
 	// library is loaded. This API struct will have all of the functions
 	// to call.
 	GDNative.api = (*options).api_struct
-	GDNative.initialized = true
+	GDNative.api11 = C.go_godot_core_1_1_api(GDNative.api)
+	GDNative.library = (*options).gd_native_library
+	GDNative.initialized.Store(true)
+	resetLibraryContext()
 
 	// Configure logging.
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
@@ -84,6 +100,9 @@ func godot_gdnative_init(options *C.godot_gdnative_init_options) {
 		log.Println("Initializing gdnative-go library.")
 	}
 
+	// Install the crash reporter as soon as we can log through Godot.
+	installCrashReporter()
+
 	// Find GDNative extensions that we support.
 	for i := 0; i < int(GDNative.api.num_extensions); i++ {
 		extension := C.cgo_get_ext(GDNative.api.extensions, C.int(i))
@@ -93,6 +112,7 @@ func godot_gdnative_init(options *C.godot_gdnative_init_options) {
 				log.Println("Found nativescript extension!")
 			}
 			NativeScript.api = (*C.godot_gdnative_ext_nativescript_api_struct)(unsafe.Pointer(extension))
+			NativeScript.api11 = C.go_godot_nativescript_1_1_api(NativeScript.api)
 		}
 	}
 }
@@ -105,8 +125,42 @@ func godot_gdnative_terminate(options *C.godot_gdnative_terminate_options) {
 	if debug {
 		log.Println("De-initializing Go library.")
 	}
+
+	// Signal every goroutine that the library is going away and give the
+	// user a last chance to clean up while the API is still available.
+	cancelLibraryContext()
+	terminateHooks.run("terminate")
+
+	// Functions queued to run on the main thread will never run now.
+	mainQueue.take()
+	resetMainThreadDispatcher()
+
+	// Report the builtins that were never freed while we can still log.
+	reportLiveBuiltins()
+
+	// Give the standard streams back before the API goes away.
+	restoreStdio()
+
+	// The library is being unloaded cleanly, there is no crash to report.
+	uninstallCrashReporter()
+
+	GDNative.initialized.Store(false)
 	GDNative.api = nil
+	GDNative.api11 = nil
+	GDNative.library = nil
 	NativeScript.api = nil
+	NativeScript.api11 = nil
+}
+
+// godot_gdnative_singleton is called by Godot right after the library is
+// initialized when the singleton option is enabled in the gdnlib file.
+//export godot_gdnative_singleton
+func godot_gdnative_singleton() {
+	if debug {
+		log.Println("Initializing Go library as singleton.")
+	}
+
+	singletonInitHooks.run("singleton init")
 }
 
 // NewEmptyVoid returns back a new C empty or void pointer
@@ -0,0 +1,214 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import "sync"
+
+// InstanceRegistry is a registry of Go values indexed by the instance ID that
+// Godot passes back to us as user data. Godot can call into our library from
+// several threads at once (Thread objects, the physics thread or resource
+// loading threads) so the registry is safe for concurrent use and lookups on
+// it never take a lock.
+type InstanceRegistry struct {
+	values sync.Map
+}
+
+// NewInstanceRegistry creates a new empty InstanceRegistry and returns a pointer to it
+func NewInstanceRegistry() *InstanceRegistry {
+	return &InstanceRegistry{}
+}
+
+// Get returns back the value registered with the given instance ID
+func (r *InstanceRegistry) Get(id string) (interface{}, bool) {
+	return r.values.Load(id)
+}
+
+// Set registers the given value with the given instance ID
+func (r *InstanceRegistry) Set(id string, value interface{}) {
+	r.values.Store(id, value)
+}
+
+// Delete removes the value registered with the given instance ID
+func (r *InstanceRegistry) Delete(id string) {
+	r.values.Delete(id)
+}
+
+// Range calls fn sequentially for each instance in the registry, if fn
+// returns false the iteration stops
+func (r *InstanceRegistry) Range(fn func(id string, value interface{}) bool) {
+	r.values.Range(func(key, value interface{}) bool {
+		return fn(key.(string), value)
+	})
+}
+
+// Len returns back the number of instances in the registry
+func (r *InstanceRegistry) Len() int {
+	length := 0
+	r.values.Range(func(_, _ interface{}) bool {
+		length++
+		return true
+	})
+
+	return length
+}
+
+// Clear removes every instance from the registry
+func (r *InstanceRegistry) Clear() {
+	r.values.Range(func(key, _ interface{}) bool {
+		r.values.Delete(key)
+		return true
+	})
+}
+
+// funcMap is the concurrent safe storage shared by all our function registries,
+// lookups on it are lock free as registered functions are written once and
+// read many times
+type funcMap struct {
+	funcs sync.Map
+}
+
+// Delete removes the function registered with the given method data
+func (m *funcMap) Delete(methodData string) {
+	m.funcs.Delete(methodData)
+}
+
+// Clear removes every function from the registry
+func (m *funcMap) Clear() {
+	m.funcs.Range(func(key, _ interface{}) bool {
+		m.funcs.Delete(key)
+		return true
+	})
+}
+
+// CreateFuncMap is a concurrent safe mapping of CreateFunc values
+type CreateFuncMap struct {
+	funcMap
+}
+
+// Get returns back the CreateFunc registered with the given method data
+func (m *CreateFuncMap) Get(methodData string) (CreateFunc, bool) {
+	fn, ok := m.funcs.Load(methodData)
+	if !ok {
+		return nil, false
+	}
+
+	return fn.(CreateFunc), true
+}
+
+// Set registers the given CreateFunc with the given method data
+func (m *CreateFuncMap) Set(methodData string, fn CreateFunc) {
+	m.funcs.Store(methodData, fn)
+}
+
+// DestroyFuncMap is a concurrent safe mapping of DestroyFunc values
+type DestroyFuncMap struct {
+	funcMap
+}
+
+// Get returns back the DestroyFunc registered with the given method data
+func (m *DestroyFuncMap) Get(methodData string) (DestroyFunc, bool) {
+	fn, ok := m.funcs.Load(methodData)
+	if !ok {
+		return nil, false
+	}
+
+	return fn.(DestroyFunc), true
+}
+
+// Set registers the given DestroyFunc with the given method data
+func (m *DestroyFuncMap) Set(methodData string, fn DestroyFunc) {
+	m.funcs.Store(methodData, fn)
+}
+
+// FreeFuncMap is a concurrent safe mapping of FreeFunc values
+type FreeFuncMap struct {
+	funcMap
+}
+
+// Get returns back the FreeFunc registered with the given method data
+func (m *FreeFuncMap) Get(methodData string) (FreeFunc, bool) {
+	fn, ok := m.funcs.Load(methodData)
+	if !ok {
+		return nil, false
+	}
+
+	return fn.(FreeFunc), true
+}
+
+// Set registers the given FreeFunc with the given method data
+func (m *FreeFuncMap) Set(methodData string, fn FreeFunc) {
+	m.funcs.Store(methodData, fn)
+}
+
+// MethodFuncMap is a concurrent safe mapping of MethodFunc values
+type MethodFuncMap struct {
+	funcMap
+}
+
+// Get returns back the MethodFunc registered with the given method data
+func (m *MethodFuncMap) Get(methodData string) (MethodFunc, bool) {
+	fn, ok := m.funcs.Load(methodData)
+	if !ok {
+		return nil, false
+	}
+
+	return fn.(MethodFunc), true
+}
+
+// Set registers the given MethodFunc with the given method data
+func (m *MethodFuncMap) Set(methodData string, fn MethodFunc) {
+	m.funcs.Store(methodData, fn)
+}
+
+// SetPropertyFuncMap is a concurrent safe mapping of SetPropertyFunc values
+type SetPropertyFuncMap struct {
+	funcMap
+}
+
+// Get returns back the SetPropertyFunc registered with the given method data
+func (m *SetPropertyFuncMap) Get(methodData string) (SetPropertyFunc, bool) {
+	fn, ok := m.funcs.Load(methodData)
+	if !ok {
+		return nil, false
+	}
+
+	return fn.(SetPropertyFunc), true
+}
+
+// Set registers the given SetPropertyFunc with the given method data
+func (m *SetPropertyFuncMap) Set(methodData string, fn SetPropertyFunc) {
+	m.funcs.Store(methodData, fn)
+}
+
+// GetPropertyFuncMap is a concurrent safe mapping of GetPropertyFunc values
+type GetPropertyFuncMap struct {
+	funcMap
+}
+
+// Get returns back the GetPropertyFunc registered with the given method data
+func (m *GetPropertyFuncMap) Get(methodData string) (GetPropertyFunc, bool) {
+	fn, ok := m.funcs.Load(methodData)
+	if !ok {
+		return nil, false
+	}
+
+	return fn.(GetPropertyFunc), true
+}
+
+// Set registers the given GetPropertyFunc with the given method data
+func (m *GetPropertyFuncMap) Set(methodData string, fn GetPropertyFunc) {
+	m.funcs.Store(methodData, fn)
+}
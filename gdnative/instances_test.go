@@ -0,0 +1,132 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestInstanceRegistryConcurrentLifecycle creates, calls and destroys
+// instances from several goroutines at once as Godot does from its threads,
+// it is meant to be run with -race
+func TestInstanceRegistryConcurrentLifecycle(t *testing.T) {
+
+	const (
+		workers   = 16
+		instances = 200
+		calls     = 10
+	)
+
+	type counter struct {
+		calls atomic.Int64
+	}
+
+	registry := NewInstanceRegistry()
+	createFuncs := new(CreateFuncMap)
+	methodFuncs := new(MethodFuncMap)
+	destroyFuncs := new(DestroyFuncMap)
+
+	var lastID, totalCalls atomic.Int64
+	createFuncs.Set("Counter", func(_ Object, _ string) string {
+		id := fmt.Sprintf("%d", lastID.Add(1))
+		registry.Set(id, &counter{})
+		return id
+	})
+	methodFuncs.Set("Counter.increment", func(_ Object, _, userData string, _ int, _ []Variant) Variant {
+		value, ok := registry.Get(userData)
+		if !ok {
+			t.Errorf("instance %s not found", userData)
+			return Variant{}
+		}
+		value.(*counter).calls.Add(1)
+		totalCalls.Add(1)
+		return Variant{}
+	})
+	destroyFuncs.Set("Counter", func(_ Object, _, userData string) {
+		value, ok := registry.Get(userData)
+		if !ok {
+			t.Errorf("instance %s destroyed twice", userData)
+			return
+		}
+		if got := value.(*counter).calls.Load(); got != calls {
+			t.Errorf("instance %s got %d calls; want %d", userData, got, calls)
+		}
+		registry.Delete(userData)
+	})
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			registry.Range(func(_ string, value interface{}) bool {
+				value.(*counter).calls.Load()
+				return true
+			})
+			registry.Len()
+		}
+	}()
+
+	var writers sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		writers.Add(1)
+		go func(worker int) {
+			defer writers.Done()
+
+			// functions are registered and removed while others are looked up
+			methodData := fmt.Sprintf("Counter.worker%d", worker)
+			methodFuncs.Set(methodData, func(Object, string, string, int, []Variant) Variant { return Variant{} })
+			defer methodFuncs.Delete(methodData)
+
+			for i := 0; i < instances; i++ {
+				create, _ := createFuncs.Get("Counter")
+				id := create(Object{}, "Counter")
+
+				increment, _ := methodFuncs.Get("Counter.increment")
+				for call := 0; call < calls; call++ {
+					increment(Object{}, "Counter.increment", id, 0, nil)
+				}
+
+				destroy, _ := destroyFuncs.Get("Counter")
+				destroy(Object{}, "Counter", id)
+			}
+		}(worker)
+	}
+
+	writers.Wait()
+	close(done)
+	readers.Wait()
+
+	if length := registry.Len(); length != 0 {
+		t.Errorf("registry has %d instances after destroying all of them", length)
+	}
+	if got, want := totalCalls.Load(), int64(workers*instances*calls); got != want {
+		t.Errorf("got %d calls; want %d", got, want)
+	}
+	if _, ok := methodFuncs.Get("Counter.worker0"); ok {
+		t.Error("worker method was not removed")
+	}
+}
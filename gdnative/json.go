@@ -0,0 +1,916 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// JSONMode selects how VariantToJSON and JSONToVariant map Variants to JSON.
+//
+// In JSONStrict mode every value is wrapped into a {"type": T, "value": V}
+// object where T is the Godot type name (as returned by GDScript's typeof
+// names: Nil, bool, int, float, String, Vector2...) so values round trip
+// without losing their type. The V part of each type is encoded as:
+//
+//	Nil                  null
+//	bool, int, float     JSON boolean and numbers
+//	String, NodePath     JSON string
+//	Vector2              [x, y]
+//	Vector3              [x, y, z]
+//	Color                [r, g, b, a]
+//	Rect2                [x, y, width, height]
+//	Transform            [xx, xy, xz, yx, yy, yz, zx, zy, zz, ox, oy, oz] (basis rows then origin)
+//	RID                  the RID id, decoded back as an empty RID
+//	Object               {"class": "Node", "id": 1234} or null, decoded back as Nil
+//	Array                array of annotated values
+//	Dictionary           array of [key, value] pairs of annotated values
+//	PoolByteArray        base64 string
+//	Pool*Array           array of plain values (Vector2, Vector3 and Color as above)
+//
+// In JSONLoose mode values are encoded as natural JSON without annotations:
+// Vector2, Vector3 and Color become {"x", "y", "z"} and {"r", "g", "b", "a"}
+// objects, Rect2 becomes {"position", "size"}, Transform becomes
+// {"basis": [[row], [row], [row]], "origin"}, RID and Object become "[RID:id]"
+// and "[Class:id]" placeholder strings and Dictionary keys that are not
+// strings are replaced by their JSON text, a *JSONError is returned if two
+// keys end up with the same text (1 and "1" for example). Decoding loose JSON only produces
+// Nil, bool, int, float, String, Array and Dictionary values.
+//
+// Transform2D, Plane, Quat, AABB and Basis values have no JSON mapping.
+type JSONMode int
+
+const (
+	// JSONStrict annotates every value with its Godot type
+	JSONStrict JSONMode = iota
+	// JSONLoose produces and reads natural JSON
+	JSONLoose
+)
+
+// JSONError is returned by VariantToJSON and JSONToVariant when a value can
+// not be encoded or decoded
+type JSONError struct {
+	// Type is the Godot type name of the offending value if known
+	Type   string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *JSONError) Error() string {
+
+	if e.Type == "" {
+		return fmt.Sprintf("gdnative: JSON: %s", e.Reason)
+	}
+
+	return fmt.Sprintf("gdnative: JSON %s: %s", e.Type, e.Reason)
+}
+
+// jsonTypeNames maps Variant types to the names used in annotated JSON
+var jsonTypeNames = map[VariantType]string{
+	VariantTypeNil:              "Nil",
+	VariantTypeBool:             "bool",
+	VariantTypeInt:              "int",
+	VariantTypeReal:             "float",
+	VariantTypeString:           "String",
+	VariantTypeVector2:          "Vector2",
+	VariantTypeRect2:            "Rect2",
+	VariantTypeVector3:          "Vector3",
+	VariantTypeTransform2D:      "Transform2D",
+	VariantTypePlane:            "Plane",
+	VariantTypeQuat:             "Quat",
+	VariantTypeAabb:             "AABB",
+	VariantTypeBasis:            "Basis",
+	VariantTypeTransform:        "Transform",
+	VariantTypeColor:            "Color",
+	VariantTypeNodePath:         "NodePath",
+	VariantTypeRid:              "RID",
+	VariantTypeObject:           "Object",
+	VariantTypeDictionary:       "Dictionary",
+	VariantTypeArray:            "Array",
+	VariantTypePoolByteArray:    "PoolByteArray",
+	VariantTypePoolIntArray:     "PoolIntArray",
+	VariantTypePoolRealArray:    "PoolRealArray",
+	VariantTypePoolStringArray:  "PoolStringArray",
+	VariantTypePoolVector2Array: "PoolVector2Array",
+	VariantTypePoolVector3Array: "PoolVector3Array",
+	VariantTypePoolColorArray:   "PoolColorArray",
+}
+
+// jsonAnnotated is the strict mode representation of a value
+type jsonAnnotated struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// jsonObject is the strict mode placeholder of a non null Object
+type jsonObject struct {
+	Class string `json:"class"`
+	ID    int64  `json:"id"`
+}
+
+type jsonVector2 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type jsonVector3 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+type jsonColor struct {
+	R float64 `json:"r"`
+	G float64 `json:"g"`
+	B float64 `json:"b"`
+	A float64 `json:"a"`
+}
+
+type jsonRect2 struct {
+	Position jsonVector2 `json:"position"`
+	Size     jsonVector2 `json:"size"`
+}
+
+type jsonTransform struct {
+	Basis  [3][3]float64 `json:"basis"`
+	Origin jsonVector3   `json:"origin"`
+}
+
+// MarshalJSON implements json.Marshaler encoding the Variant in JSONStrict
+// mode, a zero Variant is encoded as Nil
+func (gdt Variant) MarshalJSON() ([]byte, error) {
+	return VariantToJSON(gdt, JSONStrict)
+}
+
+// UnmarshalJSON implements json.Unmarshaler decoding JSONStrict encoded
+// data into a new Variant owned by the caller, the previous contents of the
+// Variant are not destroyed
+func (gdt *Variant) UnmarshalJSON(data []byte) error {
+
+	variant, err := JSONToVariant(data, JSONStrict)
+	if err != nil {
+		return err
+	}
+
+	*gdt = variant
+	return nil
+}
+
+// VariantToJSON encodes the given Variant as JSON using the given mode
+func VariantToJSON(variant Variant, mode JSONMode) ([]byte, error) {
+
+	value, err := jsonEncode(variant, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+// JSONToVariant decodes the given JSON data into a new Variant owned by the
+// caller using the given mode
+func JSONToVariant(data []byte, mode JSONMode) (Variant, error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return NewVariantNil(), err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return NewVariantNil(), &JSONError{Reason: "unexpected data after top-level value"}
+	}
+
+	if mode == JSONLoose {
+		return jsonDecodeLoose(value)
+	}
+
+	return jsonDecodeStrict(value)
+}
+
+// jsonEncode converts the given Variant into a value that encoding/json
+// serializes as described in JSONMode
+func jsonEncode(variant Variant, mode JSONMode) (interface{}, error) {
+
+	kind := VariantTypeNil
+	if variant.base != nil {
+		kind = variant.GetType()
+	}
+
+	value, err := jsonEncodeValue(variant, kind, mode)
+	if err != nil || mode == JSONLoose {
+		return value, err
+	}
+
+	return jsonAnnotated{Type: jsonTypeNames[kind], Value: value}, nil
+}
+
+func jsonEncodeValue(variant Variant, kind VariantType, mode JSONMode) (interface{}, error) {
+
+	loose := mode == JSONLoose
+	switch kind {
+	case VariantTypeNil:
+		return nil, nil
+	case VariantTypeBool:
+		return bool(variant.AsBool()), nil
+	case VariantTypeInt:
+		return int64(variant.AsInt()), nil
+	case VariantTypeReal:
+		value := float64(variant.AsReal())
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, &JSONError{Type: jsonTypeNames[kind], Reason: fmt.Sprintf("unsupported value %v", value)}
+		}
+		return value, nil
+	case VariantTypeString:
+		return string(variant.AsString()), nil
+	case VariantTypeVector2:
+		return jsonEncodeVector2(variant.AsVector2(), loose), nil
+	case VariantTypeVector3:
+		return jsonEncodeVector3(variant.AsVector3(), loose), nil
+	case VariantTypeColor:
+		return jsonEncodeColor(variant.AsColor(), loose), nil
+	case VariantTypeRect2:
+		rect := variant.AsRect2()
+		position, size := jsonVector2Of(rect.GetPosition()), jsonVector2Of(rect.GetSize())
+		if loose {
+			return jsonRect2{Position: position, Size: size}, nil
+		}
+		return []float64{position.X, position.Y, size.X, size.Y}, nil
+	case VariantTypeTransform:
+		return jsonEncodeTransform(variant.AsTransform(), loose), nil
+	case VariantTypeNodePath:
+		path := variant.AsNodePath()
+		defer path.Destroy()
+		return string(path.AsString()), nil
+	case VariantTypeRid:
+		rid := variant.AsRid()
+		if loose {
+			return fmt.Sprintf("[RID:%d]", int64(rid.GetId())), nil
+		}
+		return int64(rid.GetId()), nil
+	case VariantTypeObject:
+		return jsonEncodeObject(variant.AsObject(), loose)
+	case VariantTypeArray:
+		return jsonEncodeArray(variant, mode)
+	case VariantTypeDictionary:
+		return jsonEncodeDictionary(variant, mode)
+	case VariantTypePoolByteArray:
+		data := variant.AsBytes()
+		if !loose {
+			return data, nil
+		}
+		values := make([]int, len(data))
+		for i, value := range data {
+			values[i] = int(value)
+		}
+		return values, nil
+	case VariantTypePoolIntArray:
+		pool := variant.AsPoolIntArray()
+		defer pool.Destroy()
+		return pool.Ints(), nil
+	case VariantTypePoolRealArray:
+		pool := variant.AsPoolRealArray()
+		defer pool.Destroy()
+		return pool.Floats(), nil
+	case VariantTypePoolStringArray:
+		var values []string
+		err := Unmarshal(variant, &values)
+		return values, err
+	case VariantTypePoolVector2Array:
+		pool := variant.AsPoolVector2Array()
+		defer pool.Destroy()
+		vectors := pool.Vector2s()
+		values := make([]interface{}, len(vectors))
+		for i, vector := range vectors {
+			values[i] = jsonEncodeVector2(vector, loose)
+		}
+		return values, nil
+	case VariantTypePoolVector3Array:
+		pool := variant.AsPoolVector3Array()
+		defer pool.Destroy()
+		vectors := pool.Vector3s()
+		values := make([]interface{}, len(vectors))
+		for i, vector := range vectors {
+			values[i] = jsonEncodeVector3(vector, loose)
+		}
+		return values, nil
+	case VariantTypePoolColorArray:
+		pool := variant.AsPoolColorArray()
+		defer pool.Destroy()
+		colors := pool.Colors()
+		values := make([]interface{}, len(colors))
+		for i, color := range colors {
+			values[i] = jsonEncodeColor(color, loose)
+		}
+		return values, nil
+	}
+
+	return nil, &JSONError{Type: jsonTypeNames[kind], Reason: "type has no JSON mapping"}
+}
+
+func jsonVector2Of(vector Vector2) jsonVector2 {
+	return jsonVector2{X: float64(vector.GetX()), Y: float64(vector.GetY())}
+}
+
+func jsonVector3Of(vector Vector3) jsonVector3 {
+	return jsonVector3{
+		X: float64(vector.GetAxis(Vector3AxisX)),
+		Y: float64(vector.GetAxis(Vector3AxisY)),
+		Z: float64(vector.GetAxis(Vector3AxisZ)),
+	}
+}
+
+func jsonEncodeVector2(vector Vector2, loose bool) interface{} {
+
+	value := jsonVector2Of(vector)
+	if loose {
+		return value
+	}
+
+	return []float64{value.X, value.Y}
+}
+
+func jsonEncodeVector3(vector Vector3, loose bool) interface{} {
+
+	value := jsonVector3Of(vector)
+	if loose {
+		return value
+	}
+
+	return []float64{value.X, value.Y, value.Z}
+}
+
+func jsonEncodeColor(color Color, loose bool) interface{} {
+
+	value := jsonColor{
+		R: float64(color.GetR()),
+		G: float64(color.GetG()),
+		B: float64(color.GetB()),
+		A: float64(color.GetA()),
+	}
+	if loose {
+		return value
+	}
+
+	return []float64{value.R, value.G, value.B, value.A}
+}
+
+func jsonEncodeTransform(transform Transform, loose bool) interface{} {
+
+	var value jsonTransform
+	basis := transform.GetBasis()
+	for i := 0; i < 3; i++ {
+		row := jsonVector3Of(basis.GetRow(Int(i)))
+		value.Basis[i] = [3]float64{row.X, row.Y, row.Z}
+	}
+	value.Origin = jsonVector3Of(transform.GetOrigin())
+
+	if loose {
+		return value
+	}
+
+	values := make([]float64, 0, 12)
+	for _, row := range value.Basis {
+		values = append(values, row[:]...)
+	}
+
+	return append(values, value.Origin.X, value.Origin.Y, value.Origin.Z)
+}
+
+// jsonEncodeObject encodes a placeholder for the given Object, the class
+// name and instance id are queried using Godot's dynamic dispatch
+func jsonEncodeObject(object Object, loose bool) (interface{}, error) {
+
+	if object.getBase() == nil {
+		return nil, nil
+	}
+
+	class, err := object.Call("get_class")
+	if err != nil {
+		return nil, err
+	}
+	defer class.Destroy()
+
+	id, err := object.Call("get_instance_id")
+	if err != nil {
+		return nil, err
+	}
+	defer id.Destroy()
+
+	value := jsonObject{Class: string(class.AsString()), ID: int64(id.AsInt())}
+	if loose {
+		return fmt.Sprintf("[%s:%d]", value.Class, value.ID), nil
+	}
+
+	return value, nil
+}
+
+func jsonEncodeArray(variant Variant, mode JSONMode) (interface{}, error) {
+
+	array := variant.AsArray()
+	defer array.Destroy()
+
+	values := make([]interface{}, int(array.Size()))
+	for i := range values {
+		element := array.Get(Int(i))
+		value, err := jsonEncode(element, mode)
+		element.Destroy()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// jsonEncodeDictionary encodes the given Dictionary as a list of key value
+// pairs in strict mode or as a JSON object in loose mode, loose mode fails if
+// two keys are encoded as the same object name
+func jsonEncodeDictionary(variant Variant, mode JSONMode) (interface{}, error) {
+
+	dictionary := variant.AsDictionary()
+	defer dictionary.Destroy()
+
+	keys := dictionary.Keys()
+	defer keys.Destroy()
+
+	pairs := make([][2]interface{}, 0, int(keys.Size()))
+	object := make(map[string]interface{}, int(keys.Size()))
+	for i := 0; i < int(keys.Size()); i++ {
+		key := keys.Get(Int(i))
+		element := dictionary.Get(key)
+
+		keyValue, err := jsonEncode(key, mode)
+		var value interface{}
+		if err == nil {
+			value, err = jsonEncode(element, mode)
+		}
+
+		key.Destroy()
+		element.Destroy()
+		if err != nil {
+			return nil, err
+		}
+
+		if mode != JSONLoose {
+			pairs = append(pairs, [2]interface{}{keyValue, value})
+			continue
+		}
+
+		name, ok := keyValue.(string)
+		if !ok {
+			text, err := json.Marshal(keyValue)
+			if err != nil {
+				return nil, err
+			}
+			name = string(text)
+		}
+
+		if _, ok := object[name]; ok {
+			return nil, &JSONError{
+				Type:   "Dictionary",
+				Reason: fmt.Sprintf("keys collide on %q in loose mode, use the strict mode to keep them apart", name),
+			}
+		}
+		object[name] = value
+	}
+
+	if mode == JSONLoose {
+		return object, nil
+	}
+
+	return pairs, nil
+}
+
+// jsonDecodeStrict converts an annotated JSON value into a new Variant
+func jsonDecodeStrict(data interface{}) (Variant, error) {
+
+	annotated, ok := data.(map[string]interface{})
+	if !ok {
+		return NewVariantNil(), &JSONError{Reason: fmt.Sprintf("expected a {\"type\", \"value\"} object but got %T", data)}
+	}
+
+	name, ok := annotated["type"].(string)
+	if !ok {
+		return NewVariantNil(), &JSONError{Reason: "missing or invalid \"type\" annotation"}
+	}
+
+	value, ok := annotated["value"]
+	if !ok {
+		return NewVariantNil(), &JSONError{Type: name, Reason: "missing \"value\""}
+	}
+
+	variant, err := jsonDecodeStrictValue(name, value)
+	if err != nil {
+		var jsonErr *JSONError
+		if !errors.As(err, &jsonErr) {
+			err = &JSONError{Type: name, Reason: err.Error()}
+		} else if jsonErr.Type == "" {
+			jsonErr.Type = name
+		}
+		return NewVariantNil(), err
+	}
+
+	return variant, nil
+}
+
+func jsonDecodeStrictValue(name string, value interface{}) (Variant, error) {
+
+	switch name {
+	case "Nil":
+		return NewVariantNil(), nil
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return Variant{}, jsonValueError(name, value)
+		}
+		return NewVariantBool(Bool(b)), nil
+	case "int":
+		n, err := jsonInt(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		return NewVariantInt(Int64T(n)), nil
+	case "float":
+		f, err := jsonFloat(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		return NewVariantReal(Double(f)), nil
+	case "String":
+		s, ok := value.(string)
+		if !ok {
+			return Variant{}, jsonValueError(name, value)
+		}
+		return NewVariantString(String(s)), nil
+	case "Vector2":
+		vector, err := jsonDecodeVector2(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		return NewVariantVector2(vector), nil
+	case "Vector3":
+		vector, err := jsonDecodeVector3(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		return NewVariantVector3(vector), nil
+	case "Color":
+		color, err := jsonDecodeColor(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		return NewVariantColor(color), nil
+	case "Rect2":
+		values, err := jsonFloats(value, 4)
+		if err != nil {
+			return Variant{}, err
+		}
+		return NewVariantRect2(NewRect2(Real(values[0]), Real(values[1]), Real(values[2]), Real(values[3]))), nil
+	case "Transform":
+		values, err := jsonFloats(value, 12)
+		if err != nil {
+			return Variant{}, err
+		}
+		rows := make([]Vector3, 4)
+		for i := range rows {
+			rows[i] = NewVector3(Real(values[i*3]), Real(values[i*3+1]), Real(values[i*3+2]))
+		}
+		return NewVariantTransform(NewTransform(NewBasisWithRows(rows[0], rows[1], rows[2]), rows[3])), nil
+	case "NodePath":
+		s, ok := value.(string)
+		if !ok {
+			return Variant{}, jsonValueError(name, value)
+		}
+		path := NewNodePath(String(s))
+		defer path.Destroy()
+		return NewVariantNodePath(path), nil
+	case "RID":
+		// RIDs are only meaningful inside the running engine
+		return NewVariantRid(NewRid()), nil
+	case "Object":
+		// objects can not be recreated from JSON
+		return NewVariantNil(), nil
+	case "Array":
+		return jsonDecodeArray(value, jsonDecodeStrict)
+	case "Dictionary":
+		return jsonDecodeStrictDictionary(value)
+	case "PoolByteArray":
+		s, ok := value.(string)
+		if !ok {
+			return Variant{}, jsonValueError(name, value)
+		}
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return Variant{}, err
+		}
+		return NewVariantBytes(data), nil
+	case "PoolIntArray":
+		elements, err := jsonElements(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		values := make([]int32, len(elements))
+		for i, element := range elements {
+			n, err := jsonInt(element)
+			if err != nil {
+				return Variant{}, err
+			}
+			if n < math.MinInt32 || n > math.MaxInt32 {
+				return Variant{}, &JSONError{Type: name, Reason: fmt.Sprintf("%d overflows a 32 bits integer", n)}
+			}
+			values[i] = int32(n)
+		}
+		pool := NewPoolIntArrayFromInts(values)
+		defer pool.Destroy()
+		return NewVariantPoolIntArray(pool), nil
+	case "PoolRealArray":
+		elements, err := jsonElements(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		values := make([]float32, len(elements))
+		for i, element := range elements {
+			f, err := jsonFloat(element)
+			if err != nil {
+				return Variant{}, err
+			}
+			values[i] = float32(f)
+		}
+		pool := NewPoolRealArrayFromFloats(values)
+		defer pool.Destroy()
+		return NewVariantPoolRealArray(pool), nil
+	case "PoolStringArray":
+		elements, err := jsonElements(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		pool := NewPoolStringArray()
+		defer pool.Destroy()
+		for _, element := range elements {
+			s, ok := element.(string)
+			if !ok {
+				return Variant{}, jsonValueError(name, element)
+			}
+			pool.Append(String(s))
+		}
+		return NewVariantPoolStringArray(pool), nil
+	case "PoolVector2Array":
+		elements, err := jsonElements(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		values := make([]Vector2, len(elements))
+		for i, element := range elements {
+			if values[i], err = jsonDecodeVector2(element); err != nil {
+				return Variant{}, err
+			}
+		}
+		pool := NewPoolVector2ArrayFromVector2s(values)
+		defer pool.Destroy()
+		return NewVariantPoolVector2Array(pool), nil
+	case "PoolVector3Array":
+		elements, err := jsonElements(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		values := make([]Vector3, len(elements))
+		for i, element := range elements {
+			if values[i], err = jsonDecodeVector3(element); err != nil {
+				return Variant{}, err
+			}
+		}
+		pool := NewPoolVector3ArrayFromVector3s(values)
+		defer pool.Destroy()
+		return NewVariantPoolVector3Array(pool), nil
+	case "PoolColorArray":
+		elements, err := jsonElements(value)
+		if err != nil {
+			return Variant{}, err
+		}
+		values := make([]Color, len(elements))
+		for i, element := range elements {
+			if values[i], err = jsonDecodeColor(element); err != nil {
+				return Variant{}, err
+			}
+		}
+		pool := NewPoolColorArrayFromColors(values)
+		defer pool.Destroy()
+		return NewVariantPoolColorArray(pool), nil
+	}
+
+	return Variant{}, &JSONError{Type: name, Reason: "type has no JSON mapping"}
+}
+
+func jsonDecodeVector2(value interface{}) (Vector2, error) {
+
+	values, err := jsonFloats(value, 2)
+	if err != nil {
+		return Vector2{}, err
+	}
+
+	return NewVector2(Real(values[0]), Real(values[1])), nil
+}
+
+func jsonDecodeVector3(value interface{}) (Vector3, error) {
+
+	values, err := jsonFloats(value, 3)
+	if err != nil {
+		return Vector3{}, err
+	}
+
+	return NewVector3(Real(values[0]), Real(values[1]), Real(values[2])), nil
+}
+
+func jsonDecodeColor(value interface{}) (Color, error) {
+
+	values, err := jsonFloats(value, 4)
+	if err != nil {
+		return Color{}, err
+	}
+
+	return NewColorRgba(Real(values[0]), Real(values[1]), Real(values[2]), Real(values[3])), nil
+}
+
+func jsonDecodeArray(value interface{}, decode func(interface{}) (Variant, error)) (Variant, error) {
+
+	elements, err := jsonElements(value)
+	if err != nil {
+		return Variant{}, err
+	}
+
+	array := NewArray()
+	defer array.Destroy()
+
+	for _, element := range elements {
+		variant, err := decode(element)
+		if err != nil {
+			return Variant{}, err
+		}
+		array.Append(variant)
+		variant.Destroy()
+	}
+
+	return NewVariantArray(array), nil
+}
+
+func jsonDecodeStrictDictionary(value interface{}) (Variant, error) {
+
+	elements, err := jsonElements(value)
+	if err != nil {
+		return Variant{}, err
+	}
+
+	dictionary := NewDictionary()
+	defer dictionary.Destroy()
+
+	for _, element := range elements {
+		pair, ok := element.([]interface{})
+		if !ok || len(pair) != 2 {
+			return Variant{}, &JSONError{Type: "Dictionary", Reason: "expected [key, value] pairs"}
+		}
+
+		key, err := jsonDecodeStrict(pair[0])
+		if err != nil {
+			return Variant{}, err
+		}
+
+		item, err := jsonDecodeStrict(pair[1])
+		if err != nil {
+			key.Destroy()
+			return Variant{}, err
+		}
+
+		dictionary.Set(key, item)
+		key.Destroy()
+		item.Destroy()
+	}
+
+	return NewVariantDictionary(dictionary), nil
+}
+
+// jsonDecodeLoose converts a natural JSON value into a new Variant, integral
+// numbers become int and any other number becomes float
+func jsonDecodeLoose(value interface{}) (Variant, error) {
+
+	switch value := value.(type) {
+	case nil:
+		return NewVariantNil(), nil
+	case bool:
+		return NewVariantBool(Bool(value)), nil
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return NewVariantInt(Int64T(n)), nil
+		}
+		f, err := value.Float64()
+		if err != nil {
+			return NewVariantNil(), &JSONError{Type: "float", Reason: err.Error()}
+		}
+		return NewVariantReal(Double(f)), nil
+	case string:
+		return NewVariantString(String(value)), nil
+	case []interface{}:
+		return jsonDecodeArray(value, jsonDecodeLoose)
+	case map[string]interface{}:
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		dictionary := NewDictionary()
+		defer dictionary.Destroy()
+
+		for _, name := range names {
+			item, err := jsonDecodeLoose(value[name])
+			if err != nil {
+				return NewVariantNil(), err
+			}
+			key := NewVariantString(String(name))
+			dictionary.Set(key, item)
+			key.Destroy()
+			item.Destroy()
+		}
+		return NewVariantDictionary(dictionary), nil
+	}
+
+	return NewVariantNil(), &JSONError{Reason: fmt.Sprintf("unexpected JSON value %T", value)}
+}
+
+func jsonElements(value interface{}) ([]interface{}, error) {
+
+	elements, ok := value.([]interface{})
+	if !ok {
+		return nil, &JSONError{Reason: fmt.Sprintf("expected an array but got %T", value)}
+	}
+
+	return elements, nil
+}
+
+func jsonFloats(value interface{}, count int) ([]float64, error) {
+
+	elements, err := jsonElements(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(elements) != count {
+		return nil, &JSONError{Reason: fmt.Sprintf("expected %d numbers but got %d", count, len(elements))}
+	}
+
+	values := make([]float64, count)
+	for i, element := range elements {
+		if values[i], err = jsonFloat(element); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func jsonInt(value interface{}) (int64, error) {
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, &JSONError{Reason: fmt.Sprintf("expected an integer but got %T", value)}
+	}
+
+	return number.Int64()
+}
+
+func jsonFloat(value interface{}) (float64, error) {
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, &JSONError{Reason: fmt.Sprintf("expected a number but got %T", value)}
+	}
+
+	return number.Float64()
+}
+
+func jsonValueError(name string, value interface{}) error {
+	return &JSONError{Type: name, Reason: fmt.Sprintf("unexpected value %T", value)}
+}
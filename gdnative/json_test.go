@@ -0,0 +1,93 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+//go:build fakeapi
+
+package gdnative
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVariantToJSONLooseDictionaryKeys(t *testing.T) {
+	checkLeaks(t)
+
+	newDictionary := func(keys ...Variant) Variant {
+		dictionary := NewDictionary()
+		defer dictionary.Destroy()
+		for i, key := range keys {
+			value := NewVariantInt(Int64T(i))
+			dictionary.Set(key, value)
+			value.Destroy()
+			key.Destroy()
+		}
+		return NewVariantDictionary(dictionary)
+	}
+
+	tests := []struct {
+		name       string
+		dictionary func() Variant
+		mode       JSONMode
+		want       string
+		collides   bool
+	}{
+		{
+			"distinct keys",
+			func() Variant { return newDictionary(NewVariantInt(1), NewVariantWithString("2")) },
+			JSONLoose, `{"1":0,"2":1}`, false,
+		},
+		{
+			"int and string",
+			func() Variant { return newDictionary(NewVariantInt(1), NewVariantWithString("1")) },
+			JSONLoose, "", true,
+		},
+		{
+			"bool and string",
+			func() Variant { return newDictionary(NewVariantWithString("true"), NewVariantBool(true)) },
+			JSONLoose, "", true,
+		},
+		{
+			"int and string in strict mode",
+			func() Variant { return newDictionary(NewVariantInt(1), NewVariantWithString("1")) },
+			JSONStrict,
+			`{"type":"Dictionary","value":[[{"type":"int","value":1},{"type":"int","value":0}],[{"type":"String","value":"1"},{"type":"int","value":1}]]}`,
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dictionary := test.dictionary()
+			defer dictionary.Destroy()
+
+			data, err := VariantToJSON(dictionary, test.mode)
+			if test.collides {
+				var jsonErr *JSONError
+				if !errors.As(err, &jsonErr) {
+					t.Fatalf("VariantToJSON error = %v; want *JSONError", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("VariantToJSON: %v", err)
+			}
+			if string(data) != test.want {
+				t.Errorf("VariantToJSON = %s; want %s", data, test.want)
+			}
+		})
+	}
+}
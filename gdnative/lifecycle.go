@@ -0,0 +1,154 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// hooks is a concurrent safe list of functions run at some point of the
+// library lifecycle in the same order they were registered
+type hooks struct {
+	mu    sync.Mutex
+	funcs []func()
+}
+
+// add appends the given function to the list of hooks
+func (h *hooks) add(fn func()) {
+	if fn == nil {
+		return
+	}
+
+	h.mu.Lock()
+	h.funcs = append(h.funcs, fn)
+	h.mu.Unlock()
+}
+
+// run executes every hook in order, a panicking hook does not prevent the
+// rest of them from running
+func (h *hooks) run(stage string) {
+	h.mu.Lock()
+	funcs := make([]func(), len(h.funcs))
+	copy(funcs, h.funcs)
+	h.mu.Unlock()
+
+	for _, fn := range funcs {
+		runHook(stage, fn)
+	}
+}
+
+// runHook executes a single hook recovering from any panic
+func runHook(stage string, fn func()) {
+	defer func() {
+		if strict.Load() {
+			return
+		}
+		if r := recover(); r != nil {
+			Log.Error(fmt.Sprintf("recovered from panic in %s hook: %v\n%s", stage, r, debug.Stack()))
+		}
+	}()
+
+	fn()
+}
+
+var (
+	terminateHooks             = new(hooks)
+	singletonInitHooks         = new(hooks)
+	nativeScriptTerminateHooks = new(hooks)
+)
+
+// OnTerminate registers the given function to be called when Godot unloads
+// the library (godot_gdnative_terminate). Hooks run in the order they were
+// registered, after the library Context has been canceled and while the
+// GDNative API is still available.
+func OnTerminate(fn func()) {
+	terminateHooks.add(fn)
+}
+
+// OnSingletonInit registers the given function to be called when Godot
+// initializes the library as a singleton (godot_gdnative_singleton), this
+// only happens if the singleton option is enabled in the gdnlib file.
+func OnSingletonInit(fn func()) {
+	singletonInitHooks.add(fn)
+}
+
+// OnNativeScriptTerminate registers the given function to be called when
+// Godot terminates NativeScript (godot_nativescript_terminate). Hooks run in
+// the order they were registered and before our registries are cleared.
+func OnNativeScriptTerminate(fn func()) {
+	nativeScriptTerminateHooks.add(fn)
+}
+
+// libraryContext holds the context that is canceled when the library is
+// terminated, it is recreated every time the library is initialized
+var libraryContext = struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+}{}
+
+// Context returns back the library context, it is canceled when Godot unloads
+// the library so long running goroutines should stop when it is done
+func Context() context.Context {
+	libraryContext.mu.Lock()
+	defer libraryContext.mu.Unlock()
+
+	if libraryContext.ctx == nil {
+		libraryContext.ctx, libraryContext.cancel = context.WithCancel(context.Background())
+	}
+
+	return libraryContext.ctx
+}
+
+// resetLibraryContext creates a new library context, it is called when the
+// library is initialized as Godot can load it again after terminating it
+func resetLibraryContext() {
+	libraryContext.mu.Lock()
+	defer libraryContext.mu.Unlock()
+
+	if libraryContext.ctx != nil && libraryContext.ctx.Err() == nil {
+		return
+	}
+
+	libraryContext.ctx, libraryContext.cancel = context.WithCancel(context.Background())
+}
+
+// cancelLibraryContext cancels the library context signaling every goroutine
+// waiting on it that the library is being unloaded
+func cancelLibraryContext() {
+	libraryContext.mu.Lock()
+	defer libraryContext.mu.Unlock()
+
+	if libraryContext.cancel != nil {
+		libraryContext.cancel()
+	}
+}
+
+// clearRegistries removes every function and instance registered with our
+// registries so nothing points to the terminated NativeScript anymore
+func clearRegistries() {
+	CreateFuncRegistry.Clear()
+	DestroyFuncRegistry.Clear()
+	FreeFuncRegistry.Clear()
+	MethodFuncRegistry.Clear()
+	SetPropertyFuncRegistry.Clear()
+	GetPropertyFuncRegistry.Clear()
+	instances.Clear()
+	registeredClasses.clear()
+}
@@ -3,6 +3,7 @@ package gdnative
 /*
 #include <gdnative/string.h>
 #include <gdnative/gdnative.h>
+#include <stdlib.h>
 #include "gdnative.gen.h"
 */
 import "C"
@@ -10,14 +11,15 @@ import "C"
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"runtime"
 	"strings"
-
-	wchar "github.com/vitaminwater/cgo.wchar"
+	"unsafe"
 )
 
 // Log is used to log messages to Godot, and makes them viewable inside the
-// Godot debugger.
+// Godot debugger. Messages logged before Godot initializes the library (or
+// after it unloads it) are written to the standard error instead.
 var Log = &Logger{StackNum: 2}
 
 // Logger is a native Go structure for logging in Godot.
@@ -28,37 +30,22 @@ type Logger struct {
 
 // Println will print the given message to the Godot debugger and console.
 func (l *Logger) Println(message ...interface{}) {
-	GDNative.checkInit()
-
-	// Convert our message into a Go string.
-	goString := fmt.Sprint(message...)
-
-	// Convert the go string into a godot string
-	gdString := stringAsGodotString(goString)
-
-	// Print our string.
-	C.go_godot_print(GDNative.api, gdString)
-
-	// Free the string from memory.
-	C.go_godot_string_destroy(GDNative.api, gdString)
+	godotPrint(fmt.Sprint(message...))
 }
 
 // Warning will print a warning message to the Godot debugger and console.
 func (l *Logger) Warning(message ...interface{}) {
-	GDNative.checkInit()
 	l.log(false, message...)
 }
 
 // Error will print an error message to the Godot debugger and console.
 func (l *Logger) Error(message ...interface{}) {
-	GDNative.checkInit()
 	l.log(true, message...)
 }
 
 // Write will call Logger.Println from the given bytes, to implement the io.Writer
 // interface.
 func (l *Logger) Write(data []byte) (int, error) {
-	GDNative.checkInit()
 	buffer := bytes.NewBuffer(data)
 	line := strings.TrimRight(buffer.String(), "\n")
 	l.Println(line)
@@ -71,55 +58,68 @@ func (l *Logger) log(isError bool, message ...interface{}) {
 	// Convert the messages into a Go string.
 	goDescription := fmt.Sprint(message...)
 
-	// Get the caller filename and line number.
-	pc, file, no, ok := runtime.Caller(l.StackNum)
-	if !ok {
-		fmt.Println("Unable to get caller!")
+	// Get the caller filename and line number, if we can not get them we
+	// still log the message without them.
+	funcName, file, line := "unknown", "unknown", 0
+	if pc, callerFile, callerLine, ok := runtime.Caller(l.StackNum); ok {
+		file, line = callerFile, callerLine
+		if details := runtime.FuncForPC(pc); details != nil {
+			funcName = details.Name()
+		}
+	}
+
+	if isError {
+		godotPrintError(goDescription, funcName, file, line)
 		return
 	}
+	godotPrintWarning(goDescription, funcName, file, line)
+}
 
-	// Get the function name.
-	funcName := "unknown"
-	details := runtime.FuncForPC(pc)
-	if details != nil {
-		funcName = details.Name()
+// godotPrint prints the given message using Godot's print function or the
+// standard error if the GDNative API is not available
+func godotPrint(message string) {
+	if !GDNative.IsInitialized() {
+		fmt.Fprintln(os.Stderr, message)
+		return
 	}
 
-	// Convert the go string into a C string
-	cDescription := C.CString(goDescription)
-	cFuncName := C.CString(funcName)
-	cFile := C.CString(file)
-	cLine := C.int(no)
+	// Convert the go string into a godot string, print it and free it
+	gdString := stringAsGodotString(message)
+	C.go_godot_print(GDNative.api, gdString)
+	C.go_godot_string_destroy(GDNative.api, gdString)
+}
 
-	if isError {
-		C.go_godot_print_error(GDNative.api, cDescription, cFuncName, cFile, cLine)
+// godotPrintWarning prints the given warning using Godot's print_warning
+// function or the standard error if the GDNative API is not available
+func godotPrintWarning(message, funcName, file string, line int) {
+	if !GDNative.IsInitialized() {
+		fmt.Fprintf(os.Stderr, "WARNING: %s: %s\n   At: %s:%d\n", funcName, message, file, line)
 		return
 	}
-	C.go_godot_print_warning(GDNative.api, cDescription, cFuncName, cFile, cLine)
+
+	cDescription, cFuncName, cFile := C.CString(message), C.CString(funcName), C.CString(file)
+	defer freeCStrings(cDescription, cFuncName, cFile)
+
+	C.go_godot_print_warning(GDNative.api, cDescription, cFuncName, cFile, C.int(line))
 }
 
-func stringAsGodotString(value string) *C.godot_string {
-	var godotString C.godot_string
-	if value == "" {
-		C.go_godot_string_new(GDNative.api, &godotString)
-		return &godotString
+// godotPrintError prints the given error using Godot's print_error function
+// or the standard error if the GDNative API is not available
+func godotPrintError(message, funcName, file string, line int) {
+	if !GDNative.IsInitialized() {
+		fmt.Fprintf(os.Stderr, "ERROR: %s: %s\n   At: %s:%d\n", funcName, message, file, line)
+		return
 	}
 
-	// Convert the Go string into a wchar
-	wcharString, err := wchar.FromGoString(value)
-	if err != nil {
-		fmt.Println("Error decoding string '"+value+"': ", err)
-		C.go_godot_string_new(GDNative.api, &godotString)
-		return &godotString
-	}
+	cDescription, cFuncName, cFile := C.CString(message), C.CString(funcName), C.CString(file)
+	defer freeCStrings(cDescription, cFuncName, cFile)
 
-	// Build the Godot string with the wchar
-	C.go_godot_string_new_with_wide_string(
-		GDNative.api,
-		&godotString,
-		(*C.wchar_t)(wcharString.Pointer()),
-		C.int(len(value)),
-	)
+	C.go_godot_print_error(GDNative.api, cDescription, cFuncName, cFile, C.int(line))
+}
 
-	return &godotString
+// freeCStrings frees the given C strings
+func freeCStrings(cStrings ...*C.char) {
+	for _, cString := range cStrings {
+		C.free(unsafe.Pointer(cString))
+	}
 }
@@ -0,0 +1,172 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// LogFormat is the format used by LogHandler to render log records
+type LogFormat int
+
+const (
+	// LogFormatText renders records as key=value pairs
+	LogFormatText LogFormat = iota
+	// LogFormatJSON renders records as JSON objects
+	LogFormatJSON
+)
+
+// LogHandlerOptions are the options for a LogHandler
+type LogHandlerOptions struct {
+	// Level is the minimum level of the records that are logged, it
+	// defaults to slog.LevelInfo
+	Level slog.Leveler
+
+	// Format is the format used to render records, it defaults to
+	// LogFormatText
+	Format LogFormat
+}
+
+// LogHandler is a slog.Handler that writes records to Godot's output. Records
+// with level slog.LevelError or higher are printed with print_error, records
+// with level slog.LevelWarn or higher with print_warning and everything else
+// with print, so they show up in the editor debugger with the right caller
+// file and line. If the GDNative API is not available records are written to
+// the standard error instead. It is safe for concurrent use:
+//
+//	logger := slog.New(gdnative.NewLogHandler(&gdnative.LogHandlerOptions{
+//		Level:  slog.LevelDebug,
+//		Format: gdnative.LogFormatJSON,
+//	}))
+//	logger.Warn("low health", "player", name, "health", health)
+type LogHandler struct {
+	level  slog.Leveler
+	inner  slog.Handler
+	buffer *logBuffer
+}
+
+// logBuffer is the buffer shared by a LogHandler and all the handlers derived
+// from it, the inner handler renders records into it
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write implements io.Writer, it is only called from the inner handler while
+// the buffer lock is held
+func (b *logBuffer) Write(data []byte) (int, error) {
+	return b.buf.Write(data)
+}
+
+// NewLogHandler creates a new LogHandler with the given options, nil options
+// use the defaults
+func NewLogHandler(opts *LogHandlerOptions) *LogHandler {
+
+	if opts == nil {
+		opts = &LogHandlerOptions{}
+	}
+
+	level := opts.Level
+	if level == nil {
+		level = slog.LevelInfo
+	}
+
+	buffer := new(logBuffer)
+	innerOpts := &slog.HandlerOptions{
+		// we filter levels ourselves so the inner handler renders everything
+		Level: slog.Level(-1 << 16),
+		// Godot already shows the time and the caller so remove them
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}
+
+	var inner slog.Handler
+	switch opts.Format {
+	case LogFormatJSON:
+		inner = slog.NewJSONHandler(buffer, innerOpts)
+	default:
+		inner = slog.NewTextHandler(buffer, innerOpts)
+	}
+
+	return &LogHandler{level: level, inner: inner, buffer: buffer}
+}
+
+// Enabled implements slog.Handler
+func (h *LogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+
+	h.buffer.mu.Lock()
+	h.buffer.buf.Reset()
+	err := h.inner.Handle(ctx, record)
+	message := strings.TrimRight(h.buffer.buf.String(), "\n")
+	h.buffer.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case record.Level >= slog.LevelError:
+		funcName, file, line := recordSource(record)
+		godotPrintError(message, funcName, file, line)
+	case record.Level >= slog.LevelWarn:
+		funcName, file, line := recordSource(record)
+		godotPrintWarning(message, funcName, file, line)
+	default:
+		godotPrint(message)
+	}
+
+	return nil
+}
+
+// WithAttrs implements slog.Handler
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{level: h.level, inner: h.inner.WithAttrs(attrs), buffer: h.buffer}
+}
+
+// WithGroup implements slog.Handler
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{level: h.level, inner: h.inner.WithGroup(name), buffer: h.buffer}
+}
+
+// recordSource returns back the function name, file and line of the code
+// that created the given record
+func recordSource(record slog.Record) (string, string, int) {
+
+	if record.PC == 0 {
+		return "unknown", "unknown", 0
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+	if frame.Function == "" {
+		return "unknown", frame.File, frame.Line
+	}
+
+	return frame.Function, frame.File, frame.Line
+}
@@ -0,0 +1,1213 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VariantMarshaler is the interface implemented by types that can convert
+// themselves into a Variant, Marshal uses it before any other conversion
+type VariantMarshaler interface {
+	MarshalVariant() (Variant, error)
+}
+
+// VariantUnmarshaler is the interface implemented by types that can store a
+// Variant into themselves, Unmarshal uses it before any other conversion.
+// The given Variant belongs to the caller, implementations must copy it if
+// they want to keep it
+type VariantUnmarshaler interface {
+	UnmarshalVariant(Variant) error
+}
+
+// UnsupportedTypeError is returned by Marshal when it finds a Go value that
+// can not be converted into a Variant
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+// Error implements the error interface
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("gdnative: unsupported type %s", e.Type)
+}
+
+// MarshalerError is returned by Marshal when a value can be converted into a
+// Variant but its contents are not valid (for example an unsigned integer
+// that does not fit in a Godot int)
+type MarshalerError struct {
+	Type   reflect.Type
+	Reason string
+}
+
+// Error implements the error interface
+func (e *MarshalerError) Error() string {
+	return fmt.Sprintf("gdnative: can not marshal %s: %s", e.Type, e.Reason)
+}
+
+// InvalidUnmarshalError is returned by Unmarshal when the given Go value is
+// not a non nil pointer
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+// Error implements the error interface
+func (e *InvalidUnmarshalError) Error() string {
+
+	if e.Type == nil {
+		return "gdnative: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return fmt.Sprintf("gdnative: Unmarshal(non-pointer %s)", e.Type)
+	}
+
+	return fmt.Sprintf("gdnative: Unmarshal(nil %s)", e.Type)
+}
+
+// UnmarshalTypeError is returned by Unmarshal when a Variant can not be
+// stored into a Go value of the given type
+type UnmarshalTypeError struct {
+	VariantType VariantType
+	Type        reflect.Type
+	// Field is the path of the struct field or element that failed
+	Field  string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *UnmarshalTypeError) Error() string {
+
+	message := fmt.Sprintf("gdnative: can not unmarshal %s into Go value of type %s", variantTypeName(e.VariantType), e.Type)
+	if e.Field != "" {
+		message = fmt.Sprintf("gdnative: can not unmarshal %s into Go struct field %s of type %s", variantTypeName(e.VariantType), e.Field, e.Type)
+	}
+
+	if e.Reason != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Reason)
+	}
+
+	return message
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	variantType  = reflect.TypeOf(Variant{})
+
+	variantMarshalerType   = reflect.TypeOf((*VariantMarshaler)(nil)).Elem()
+	variantUnmarshalerType = reflect.TypeOf((*VariantUnmarshaler)(nil)).Elem()
+	textMarshalerType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType    = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Marshal converts the given Go value into a new Variant owned by the caller.
+//
+// Values implementing VariantMarshaler are converted using their own
+// MarshalVariant method, values implementing encoding.TextMarshaler are
+// converted into a String.
+//
+// Booleans, integers, floats and strings are converted into their Godot
+// counterparts. Structs and maps are converted into a Dictionary, struct
+// fields use the field name as key unless a `godot:"name"` tag is given, a
+// `godot:"-"` tag skips the field and the omitempty option skips empty
+// values. Byte, string, Vector2, Vector3 and Color slices are converted into
+// the matching Pool*Array, so are int8, int16, int32, uint16 and float32
+// slices as they fit in the 32 bits PoolIntArray and PoolRealArray, any other
+// slice or array (including int, int64 and float64 ones) is converted into an
+// Array. Slices and arrays of elements implementing VariantMarshaler or
+// encoding.TextMarshaler are always converted into an Array of the marshaled
+// elements. Nil pointers, interfaces, slices and maps are converted into a nil
+// Variant. time.Time values are converted into RFC 3339
+// strings and time.Duration values into float seconds. Godot types (Vector2,
+// Object, Dictionary...) and Variants are stored as they are.
+func Marshal(v interface{}) (Variant, error) {
+	if v == nil {
+		return NewVariantNil(), nil
+	}
+
+	return marshalValue(reflect.ValueOf(v))
+}
+
+// marshalValue converts the given reflected value into a Variant
+func marshalValue(value reflect.Value) (Variant, error) {
+
+	if !value.IsValid() {
+		return NewVariantNil(), nil
+	}
+
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return NewVariantNil(), nil
+	}
+
+	if marshaler, ok := interfaceOf(value, variantMarshalerType).(VariantMarshaler); ok {
+		variant, err := marshaler.MarshalVariant()
+		if err != nil {
+			return NewVariantNil(), &MarshalerError{Type: value.Type(), Reason: err.Error()}
+		}
+		return variant, nil
+	}
+
+	switch value.Type() {
+	case timeType:
+		return NewVariantWithString(String(value.Interface().(time.Time).Format(time.RFC3339Nano))), nil
+	case durationType:
+		return NewVariantReal(Double(value.Interface().(time.Duration).Seconds())), nil
+	}
+
+	if variant, ok := marshalGodotValue(value); ok {
+		return variant, nil
+	}
+
+	if marshaler, ok := interfaceOf(value, textMarshalerType).(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return NewVariantNil(), &MarshalerError{Type: value.Type(), Reason: err.Error()}
+		}
+		return NewVariantWithString(String(text)), nil
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return NewVariantBool(Bool(value.Bool())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewVariantInt(Int64T(value.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > math.MaxInt64 {
+			return NewVariantNil(), &MarshalerError{Type: value.Type(), Reason: fmt.Sprintf("%d overflows Godot int", value.Uint())}
+		}
+		return NewVariantInt(Int64T(value.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return NewVariantReal(Double(value.Float())), nil
+	case reflect.String:
+		return NewVariantWithString(String(value.String())), nil
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return NewVariantNil(), nil
+		}
+		return marshalValue(value.Elem())
+	case reflect.Slice:
+		if value.IsNil() {
+			return NewVariantNil(), nil
+		}
+		return marshalSequence(value)
+	case reflect.Array:
+		return marshalSequence(value)
+	case reflect.Map:
+		if value.IsNil() {
+			return NewVariantNil(), nil
+		}
+		return marshalMap(value)
+	case reflect.Struct:
+		return marshalStruct(value)
+	}
+
+	return NewVariantNil(), &UnsupportedTypeError{Type: value.Type()}
+}
+
+// interfaceOf returns back the given value (or a pointer to it if the value
+// is addressable) as an interface{} if it implements the given interface type,
+// otherwise it returns nil
+func interfaceOf(value reflect.Value, iface reflect.Type) interface{} {
+
+	if value.Kind() != reflect.Interface && value.CanInterface() && value.Type().Implements(iface) {
+		return value.Interface()
+	}
+
+	if value.CanAddr() && value.Addr().CanInterface() && value.Addr().Type().Implements(iface) {
+		return value.Addr().Interface()
+	}
+
+	return nil
+}
+
+// implementsAny returns true if the given type or a pointer to it implements
+// any of the given interfaces
+func implementsAny(kind reflect.Type, ifaces ...reflect.Type) bool {
+
+	for _, iface := range ifaces {
+		if kind.Implements(iface) || reflect.PointerTo(kind).Implements(iface) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// marshalGodotValue converts Godot types into a Variant holding them
+func marshalGodotValue(value reflect.Value) (Variant, bool) {
+
+	if !value.CanInterface() {
+		return Variant{}, false
+	}
+
+	switch godotValue := value.Interface().(type) {
+	case Variant:
+		return NewVariantCopy(godotValue), true
+	case String:
+		return NewVariantWithString(godotValue), true
+	case Vector2:
+		return NewVariantVector2(godotValue), true
+	case Vector3:
+		return NewVariantVector3(godotValue), true
+	case Rect2:
+		return NewVariantRect2(godotValue), true
+	case Transform2D:
+		return NewVariantTransform2D(godotValue), true
+	case Plane:
+		return NewVariantPlane(godotValue), true
+	case Quat:
+		return NewVariantQuat(godotValue), true
+	case Aabb:
+		return NewVariantAabb(godotValue), true
+	case Basis:
+		return NewVariantBasis(godotValue), true
+	case Transform:
+		return NewVariantTransform(godotValue), true
+	case Color:
+		return NewVariantColor(godotValue), true
+	case NodePath:
+		return NewVariantNodePath(godotValue), true
+	case Rid:
+		return NewVariantRid(godotValue), true
+	case Object:
+		return NewVariantObject(godotValue), true
+	case Dictionary:
+		return NewVariantDictionary(godotValue), true
+	case Array:
+		return NewVariantArray(godotValue), true
+	case PoolByteArray:
+		return NewVariantPoolByteArray(godotValue), true
+	case PoolIntArray:
+		return NewVariantPoolIntArray(godotValue), true
+	case PoolRealArray:
+		return NewVariantPoolRealArray(godotValue), true
+	case PoolStringArray:
+		return NewVariantPoolStringArray(godotValue), true
+	case PoolVector2Array:
+		return NewVariantPoolVector2Array(godotValue), true
+	case PoolVector3Array:
+		return NewVariantPoolVector3Array(godotValue), true
+	case PoolColorArray:
+		return NewVariantPoolColorArray(godotValue), true
+	}
+
+	return Variant{}, false
+}
+
+// marshalSequence converts slices and arrays into the matching Pool*Array or
+// into an Array if there is no pool for their element type. PoolIntArray and
+// PoolRealArray hold 32 bits values so they are only used for element types
+// that fit in them, wider integers and floats are stored in an Array of int
+// and real Variants to not lose range nor precision. Elements implementing
+// VariantMarshaler or encoding.TextMarshaler are always marshaled one by one
+// into an Array
+func marshalSequence(value reflect.Value) (Variant, error) {
+
+	elemType := value.Type().Elem()
+	if implementsAny(elemType, variantMarshalerType, textMarshalerType) {
+		return marshalElements(value)
+	}
+
+	switch elemType {
+	case reflect.TypeOf(Vector2{}):
+		values := make([]Vector2, value.Len())
+		reflect.Copy(reflect.ValueOf(values), value)
+		pool := NewPoolVector2ArrayFromVector2s(values)
+		defer pool.Destroy()
+		return NewVariantPoolVector2Array(pool), nil
+	case reflect.TypeOf(Vector3{}):
+		values := make([]Vector3, value.Len())
+		reflect.Copy(reflect.ValueOf(values), value)
+		pool := NewPoolVector3ArrayFromVector3s(values)
+		defer pool.Destroy()
+		return NewVariantPoolVector3Array(pool), nil
+	case reflect.TypeOf(Color{}):
+		values := make([]Color, value.Len())
+		reflect.Copy(reflect.ValueOf(values), value)
+		pool := NewPoolColorArrayFromColors(values)
+		defer pool.Destroy()
+		return NewVariantPoolColorArray(pool), nil
+	}
+
+	switch elemType.Kind() {
+	case reflect.Uint8:
+		if value.Kind() == reflect.Slice {
+			return NewVariantBytes(value.Bytes()), nil
+		}
+
+		values := make([]byte, value.Len())
+		reflect.Copy(reflect.ValueOf(values), value)
+		return NewVariantBytes(values), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint16:
+		values := make([]int32, value.Len())
+		for i := range values {
+			if element := value.Index(i); element.Kind() == reflect.Uint16 {
+				values[i] = int32(element.Uint())
+			} else {
+				values[i] = int32(element.Int())
+			}
+		}
+		pool := NewPoolIntArrayFromInts(values)
+		defer pool.Destroy()
+		return NewVariantPoolIntArray(pool), nil
+	case reflect.Float32:
+		values := make([]float32, value.Len())
+		for i := range values {
+			values[i] = float32(value.Index(i).Float())
+		}
+		pool := NewPoolRealArrayFromFloats(values)
+		defer pool.Destroy()
+		return NewVariantPoolRealArray(pool), nil
+	case reflect.String:
+		pool := NewPoolStringArray()
+		defer pool.Destroy()
+		for i := 0; i < value.Len(); i++ {
+			pool.Append(String(value.Index(i).String()))
+		}
+		return NewVariantPoolStringArray(pool), nil
+	}
+
+	return marshalElements(value)
+}
+
+// marshalElements converts each element of the given slice or array into a
+// Variant and stores them into an Array
+func marshalElements(value reflect.Value) (Variant, error) {
+
+	array := NewArray()
+	defer array.Destroy()
+	for i := 0; i < value.Len(); i++ {
+		element, err := marshalValue(value.Index(i))
+		if err != nil {
+			return NewVariantNil(), err
+		}
+		array.Append(element)
+		element.Destroy()
+	}
+
+	return NewVariantArray(array), nil
+}
+
+// marshalMap converts a map into a Dictionary
+func marshalMap(value reflect.Value) (Variant, error) {
+
+	dictionary := NewDictionary()
+	defer dictionary.Destroy()
+
+	iter := value.MapRange()
+	for iter.Next() {
+		key, err := marshalValue(iter.Key())
+		if err != nil {
+			return NewVariantNil(), err
+		}
+
+		element, err := marshalValue(iter.Value())
+		if err != nil {
+			key.Destroy()
+			return NewVariantNil(), err
+		}
+
+		dictionary.Set(key, element)
+		key.Destroy()
+		element.Destroy()
+	}
+
+	return NewVariantDictionary(dictionary), nil
+}
+
+// marshalStruct converts a struct into a Dictionary using its fields tags
+func marshalStruct(value reflect.Value) (Variant, error) {
+
+	dictionary := NewDictionary()
+	defer dictionary.Destroy()
+
+	for _, field := range structFields(value.Type()) {
+		fieldValue, ok := fieldByIndex(value, field.index)
+		if !ok || (field.omitEmpty && fieldValue.IsZero()) {
+			continue
+		}
+
+		element, err := marshalValue(fieldValue)
+		if err != nil {
+			return NewVariantNil(), err
+		}
+
+		key := NewVariantWithString(String(field.name))
+		dictionary.Set(key, element)
+		key.Destroy()
+		element.Destroy()
+	}
+
+	return NewVariantDictionary(dictionary), nil
+}
+
+// fieldByIndex returns back the nested field with the given index, it returns
+// false if the field is reached through a nil embedded pointer
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+
+	for i, position := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(position)
+	}
+
+	return value, true
+}
+
+// Unmarshal stores the given Variant into the Go value pointed by v, it
+// follows the same conversion rules that Marshal uses. Values implementing
+// VariantUnmarshaler or encoding.TextUnmarshaler (from a String) are stored
+// using their own methods. Numbers can be stored
+// into any numeric Go value as long as they fit on it, Arrays and any
+// Pool*Array can be stored into slices and arrays and Dictionaries into maps
+// and structs (missing keys leave the field untouched). When v points to an
+// empty interface the Variant is stored using its natural Go type: bool,
+// int64, float64, string, []interface{}, map[string]interface{} (or
+// map[interface{}]interface{} if any key is not a string), typed slices for
+// pool arrays and the Godot type itself for everything else.
+func Unmarshal(variant Variant, v interface{}) error {
+
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+
+	return unmarshalValue(variant, value.Elem(), "")
+}
+
+// unmarshalValue stores the given Variant into the given settable value
+func unmarshalValue(variant Variant, value reflect.Value, field string) error {
+
+	kind := variant.GetType()
+	typeError := func(reason string) error {
+		return &UnmarshalTypeError{VariantType: kind, Type: value.Type(), Field: field, Reason: reason}
+	}
+
+	if value.Kind() != reflect.Ptr {
+		if unmarshaler, ok := interfaceOf(value, variantUnmarshalerType).(VariantUnmarshaler); ok {
+			if err := unmarshaler.UnmarshalVariant(variant); err != nil {
+				return typeError(err.Error())
+			}
+			return nil
+		}
+	}
+
+	switch value.Type() {
+	case timeType:
+		return unmarshalTime(variant, value, typeError)
+	case durationType:
+		if kind != VariantTypeInt && kind != VariantTypeReal {
+			return typeError("")
+		}
+		value.SetInt(int64(float64(variant.AsReal()) * float64(time.Second)))
+		return nil
+	}
+
+	if ok, err := unmarshalGodotValue(variant, value); ok {
+		if err != nil {
+			return typeError(err.Error())
+		}
+		return nil
+	}
+
+	if value.Kind() != reflect.Ptr {
+		if unmarshaler, ok := interfaceOf(value, textUnmarshalerType).(encoding.TextUnmarshaler); ok {
+			if kind != VariantTypeString {
+				return typeError("expected a String")
+			}
+			if err := unmarshaler.UnmarshalText([]byte(string(variant.AsString()))); err != nil {
+				return typeError(err.Error())
+			}
+			return nil
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Interface:
+		if value.NumMethod() != 0 {
+			return typeError("only empty interfaces are supported")
+		}
+		natural, err := naturalValue(variant)
+		if err != nil {
+			return err
+		}
+		if natural == nil {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+		value.Set(reflect.ValueOf(natural))
+		return nil
+	case reflect.Ptr:
+		if kind == VariantTypeNil {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return unmarshalValue(variant, value.Elem(), field)
+	case reflect.Bool:
+		if kind != VariantTypeBool {
+			return typeError("")
+		}
+		value.SetBool(bool(variant.AsBool()))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := variantInt(variant)
+		if err != nil {
+			return typeError(err.Error())
+		}
+		if value.OverflowInt(number) {
+			return typeError(fmt.Sprintf("%d overflows %s", number, value.Type()))
+		}
+		value.SetInt(number)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		number, err := variantInt(variant)
+		if err != nil {
+			return typeError(err.Error())
+		}
+		if number < 0 || value.OverflowUint(uint64(number)) {
+			return typeError(fmt.Sprintf("%d overflows %s", number, value.Type()))
+		}
+		value.SetUint(uint64(number))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if kind != VariantTypeInt && kind != VariantTypeReal {
+			return typeError("")
+		}
+		number := float64(variant.AsReal())
+		if value.OverflowFloat(number) {
+			return typeError(fmt.Sprintf("%g overflows %s", number, value.Type()))
+		}
+		value.SetFloat(number)
+		return nil
+	case reflect.String:
+		if kind != VariantTypeString {
+			return typeError("")
+		}
+		value.SetString(string(variant.AsString()))
+		return nil
+	case reflect.Slice, reflect.Array:
+		return unmarshalSequence(variant, value, field, typeError)
+	case reflect.Map:
+		return unmarshalMap(variant, value, field, typeError)
+	case reflect.Struct:
+		return unmarshalStruct(variant, value, field, typeError)
+	}
+
+	return typeError("unsupported Go type")
+}
+
+// unmarshalGodotValue stores the Variant into Godot typed values, it returns
+// false if the given value is not a Godot type
+func unmarshalGodotValue(variant Variant, value reflect.Value) (bool, error) {
+
+	kind := variant.GetType()
+	expect := func(expected VariantType) error {
+		if kind != expected {
+			return fmt.Errorf("expected %s", variantTypeName(expected))
+		}
+		return nil
+	}
+
+	var result interface{}
+	var err error
+	switch value.Type() {
+	case variantType:
+		result = NewVariantCopy(variant)
+	case reflect.TypeOf(String("")):
+		if err = expect(VariantTypeString); err == nil {
+			result = variant.AsString()
+		}
+	case reflect.TypeOf(Vector2{}):
+		if err = expect(VariantTypeVector2); err == nil {
+			result = variant.AsVector2()
+		}
+	case reflect.TypeOf(Vector3{}):
+		if err = expect(VariantTypeVector3); err == nil {
+			result = variant.AsVector3()
+		}
+	case reflect.TypeOf(Rect2{}):
+		if err = expect(VariantTypeRect2); err == nil {
+			result = variant.AsRect2()
+		}
+	case reflect.TypeOf(Transform2D{}):
+		if err = expect(VariantTypeTransform2D); err == nil {
+			result = variant.AsTransform2D()
+		}
+	case reflect.TypeOf(Plane{}):
+		if err = expect(VariantTypePlane); err == nil {
+			result = variant.AsPlane()
+		}
+	case reflect.TypeOf(Quat{}):
+		if err = expect(VariantTypeQuat); err == nil {
+			result = variant.AsQuat()
+		}
+	case reflect.TypeOf(Aabb{}):
+		if err = expect(VariantTypeAabb); err == nil {
+			result = variant.AsAabb()
+		}
+	case reflect.TypeOf(Basis{}):
+		if err = expect(VariantTypeBasis); err == nil {
+			result = variant.AsBasis()
+		}
+	case reflect.TypeOf(Transform{}):
+		if err = expect(VariantTypeTransform); err == nil {
+			result = variant.AsTransform()
+		}
+	case reflect.TypeOf(Color{}):
+		if err = expect(VariantTypeColor); err == nil {
+			result = variant.AsColor()
+		}
+	case reflect.TypeOf(NodePath{}):
+		if err = expect(VariantTypeNodePath); err == nil {
+			result = variant.AsNodePath()
+		}
+	case reflect.TypeOf(Rid{}):
+		if err = expect(VariantTypeRid); err == nil {
+			result = variant.AsRid()
+		}
+	case reflect.TypeOf(Object{}):
+		if kind != VariantTypeObject && kind != VariantTypeNil {
+			err = expect(VariantTypeObject)
+		} else {
+			result = variant.AsObject()
+		}
+	case reflect.TypeOf(Dictionary{}):
+		if err = expect(VariantTypeDictionary); err == nil {
+			result = variant.AsDictionary()
+		}
+	case reflect.TypeOf(Array{}):
+		result = variant.AsArray()
+	case reflect.TypeOf(PoolByteArray{}):
+		result = variant.AsPoolByteArray()
+	case reflect.TypeOf(PoolIntArray{}):
+		result = variant.AsPoolIntArray()
+	case reflect.TypeOf(PoolRealArray{}):
+		result = variant.AsPoolRealArray()
+	case reflect.TypeOf(PoolStringArray{}):
+		result = variant.AsPoolStringArray()
+	case reflect.TypeOf(PoolVector2Array{}):
+		result = variant.AsPoolVector2Array()
+	case reflect.TypeOf(PoolVector3Array{}):
+		result = variant.AsPoolVector3Array()
+	case reflect.TypeOf(PoolColorArray{}):
+		result = variant.AsPoolColorArray()
+	default:
+		return false, nil
+	}
+
+	if err != nil {
+		return true, err
+	}
+
+	value.Set(reflect.ValueOf(result))
+	return true, nil
+}
+
+// unmarshalTime stores a String (RFC 3339) or a number (Unix seconds) into a time.Time
+func unmarshalTime(variant Variant, value reflect.Value, typeError func(string) error) error {
+
+	switch variant.GetType() {
+	case VariantTypeString:
+		parsed, err := time.Parse(time.RFC3339Nano, string(variant.AsString()))
+		if err != nil {
+			return typeError(err.Error())
+		}
+		value.Set(reflect.ValueOf(parsed))
+	case VariantTypeInt:
+		value.Set(reflect.ValueOf(time.Unix(int64(variant.AsInt()), 0)))
+	case VariantTypeReal:
+		seconds, fraction := math.Modf(float64(variant.AsReal()))
+		value.Set(reflect.ValueOf(time.Unix(int64(seconds), int64(fraction*float64(time.Second)))))
+	default:
+		return typeError("")
+	}
+
+	return nil
+}
+
+// variantInt returns back the integer held by the given Variant, whole reals
+// are accepted as well as GDScript does not always keep the distinction
+func variantInt(variant Variant) (int64, error) {
+
+	switch variant.GetType() {
+	case VariantTypeInt:
+		return int64(variant.AsInt()), nil
+	case VariantTypeReal:
+		number := float64(variant.AsReal())
+		if number != math.Trunc(number) || number < math.MinInt64 || number > math.MaxInt64 {
+			return 0, fmt.Errorf("%g is not an integer", number)
+		}
+		return int64(number), nil
+	}
+
+	return 0, fmt.Errorf("expected an integer")
+}
+
+// isSequenceType returns true if the given variant type can be stored into
+// Go slices and arrays
+func isSequenceType(kind VariantType) bool {
+
+	switch kind {
+	case VariantTypeArray, VariantTypePoolByteArray, VariantTypePoolIntArray,
+		VariantTypePoolRealArray, VariantTypePoolStringArray, VariantTypePoolVector2Array,
+		VariantTypePoolVector3Array, VariantTypePoolColorArray:
+		return true
+	}
+
+	return false
+}
+
+// unmarshalSequence stores an Array or any Pool*Array into a slice or array
+func unmarshalSequence(variant Variant, value reflect.Value, field string, typeError func(string) error) error {
+
+	kind := variant.GetType()
+	if kind == VariantTypeNil && value.Kind() == reflect.Slice {
+		value.Set(reflect.Zero(value.Type()))
+		return nil
+	}
+
+	if !isSequenceType(kind) {
+		return typeError("")
+	}
+
+	// pool arrays are copied in one go into matching slices and arrays
+	if ok, err := unmarshalPoolArray(variant, value, typeError); ok {
+		return err
+	}
+
+	// Godot converts any pool array into an Array for us
+	array := variant.AsArray()
+	defer array.Destroy()
+
+	length := int(array.Size())
+	if value.Kind() == reflect.Array {
+		if length != value.Len() {
+			return typeError(fmt.Sprintf("expected %d elements but got %d", value.Len(), length))
+		}
+	} else {
+		value.Set(reflect.MakeSlice(value.Type(), length, length))
+	}
+
+	for i := 0; i < length; i++ {
+		element := array.Get(Int(i))
+		err := unmarshalValue(element, value.Index(i), fmt.Sprintf("%s[%d]", field, i))
+		element.Destroy()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unmarshalPoolArray stores a Pool*Array into a slice or array of a matching
+// element type copying the contents of the pool in one go, it returns false
+// if the pool and the Go value do not match or the elements implement
+// VariantUnmarshaler or encoding.TextUnmarshaler
+func unmarshalPoolArray(variant Variant, value reflect.Value, typeError func(string) error) (bool, error) {
+
+	var values reflect.Value
+	elemType := value.Type().Elem()
+	if implementsAny(elemType, variantUnmarshalerType, textUnmarshalerType) {
+		return false, nil
+	}
+
+	switch kind := variant.GetType(); {
+	case kind == VariantTypePoolByteArray && elemType.Kind() == reflect.Uint8:
+		values = reflect.ValueOf(variant.AsBytes())
+	case kind == VariantTypePoolVector2Array && elemType == reflect.TypeOf(Vector2{}):
+		pool := variant.AsPoolVector2Array()
+		defer pool.Destroy()
+		values = reflect.ValueOf(pool.Vector2s())
+	case kind == VariantTypePoolVector3Array && elemType == reflect.TypeOf(Vector3{}):
+		pool := variant.AsPoolVector3Array()
+		defer pool.Destroy()
+		values = reflect.ValueOf(pool.Vector3s())
+	case kind == VariantTypePoolColorArray && elemType == reflect.TypeOf(Color{}):
+		pool := variant.AsPoolColorArray()
+		defer pool.Destroy()
+		values = reflect.ValueOf(pool.Colors())
+	case kind == VariantTypePoolRealArray && (elemType.Kind() == reflect.Float32 || elemType.Kind() == reflect.Float64):
+		pool := variant.AsPoolRealArray()
+		defer pool.Destroy()
+		values = reflect.ValueOf(pool.Floats())
+	case kind == VariantTypePoolIntArray && reflect.Int <= elemType.Kind() && elemType.Kind() <= reflect.Uint64:
+		pool := variant.AsPoolIntArray()
+		defer pool.Destroy()
+		values = reflect.ValueOf(pool.Ints())
+	default:
+		return false, nil
+	}
+
+	length := values.Len()
+	if value.Kind() == reflect.Array {
+		if length != value.Len() {
+			return true, typeError(fmt.Sprintf("expected %d elements but got %d", value.Len(), length))
+		}
+	} else {
+		value.Set(reflect.MakeSlice(value.Type(), length, length))
+	}
+
+	// same element types are copied directly, numbers are converted one by one
+	if values.Type().Elem() == elemType {
+		reflect.Copy(value, values)
+		return true, nil
+	}
+
+	for i := 0; i < length; i++ {
+		element, target := values.Index(i), value.Index(i)
+		if target.CanFloat() {
+			target.SetFloat(element.Float())
+			continue
+		}
+
+		var number int64
+		if element.CanInt() {
+			number = element.Int()
+		} else {
+			number = int64(element.Uint())
+		}
+
+		switch {
+		case target.CanInt():
+			if target.OverflowInt(number) {
+				return true, typeError(fmt.Sprintf("%d overflows %s", number, elemType))
+			}
+			target.SetInt(number)
+		case target.CanUint():
+			if number < 0 || target.OverflowUint(uint64(number)) {
+				return true, typeError(fmt.Sprintf("%d overflows %s", number, elemType))
+			}
+			target.SetUint(uint64(number))
+		}
+	}
+
+	return true, nil
+}
+
+// unmarshalMap stores a Dictionary into a map
+func unmarshalMap(variant Variant, value reflect.Value, field string, typeError func(string) error) error {
+
+	kind := variant.GetType()
+	if kind == VariantTypeNil {
+		value.Set(reflect.Zero(value.Type()))
+		return nil
+	}
+
+	if kind != VariantTypeDictionary {
+		return typeError("")
+	}
+
+	dictionary := variant.AsDictionary()
+	defer dictionary.Destroy()
+
+	keys := dictionary.Keys()
+	defer keys.Destroy()
+
+	if value.IsNil() {
+		value.Set(reflect.MakeMap(value.Type()))
+	}
+
+	for i := 0; i < int(keys.Size()); i++ {
+		key := keys.Get(Int(i))
+		element := dictionary.Get(key)
+
+		mapKey := reflect.New(value.Type().Key()).Elem()
+		mapValue := reflect.New(value.Type().Elem()).Elem()
+		err := unmarshalValue(key, mapKey, fmt.Sprintf("%s[key]", field))
+		if err == nil {
+			err = unmarshalValue(element, mapValue, fmt.Sprintf("%s[%v]", field, mapKey))
+		}
+
+		key.Destroy()
+		element.Destroy()
+		if err != nil {
+			return err
+		}
+
+		value.SetMapIndex(mapKey, mapValue)
+	}
+
+	return nil
+}
+
+// unmarshalStruct stores a Dictionary into a struct using its fields tags
+func unmarshalStruct(variant Variant, value reflect.Value, field string, typeError func(string) error) error {
+
+	if variant.GetType() != VariantTypeDictionary {
+		return typeError("")
+	}
+
+	dictionary := variant.AsDictionary()
+	defer dictionary.Destroy()
+
+	for _, info := range structFields(value.Type()) {
+		key := NewVariantWithString(String(info.name))
+		if !bool(dictionary.Has(key)) {
+			key.Destroy()
+			continue
+		}
+
+		element := dictionary.Get(key)
+		key.Destroy()
+
+		fieldPath := info.name
+		if field != "" {
+			fieldPath = field + "." + info.name
+		}
+
+		err := unmarshalValue(element, allocFieldByIndex(value, info.index), fieldPath)
+		element.Destroy()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// allocFieldByIndex returns back the nested field with the given index
+// allocating any nil embedded pointer in the way
+func allocFieldByIndex(value reflect.Value, index []int) reflect.Value {
+
+	for i, position := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(position)
+	}
+
+	return value
+}
+
+// naturalValue converts the given Variant into its natural Go representation
+func naturalValue(variant Variant) (interface{}, error) {
+
+	switch variant.GetType() {
+	case VariantTypeNil:
+		return nil, nil
+	case VariantTypeBool:
+		return bool(variant.AsBool()), nil
+	case VariantTypeInt:
+		return int64(variant.AsInt()), nil
+	case VariantTypeReal:
+		return float64(variant.AsReal()), nil
+	case VariantTypeString:
+		return string(variant.AsString()), nil
+	case VariantTypeArray:
+		var values []interface{}
+		err := Unmarshal(variant, &values)
+		return values, err
+	case VariantTypeDictionary:
+		return naturalDictionary(variant)
+	case VariantTypePoolByteArray:
+		var values []byte
+		err := Unmarshal(variant, &values)
+		return values, err
+	case VariantTypePoolIntArray:
+		var values []int32
+		err := Unmarshal(variant, &values)
+		return values, err
+	case VariantTypePoolRealArray:
+		var values []float32
+		err := Unmarshal(variant, &values)
+		return values, err
+	case VariantTypePoolStringArray:
+		var values []string
+		err := Unmarshal(variant, &values)
+		return values, err
+	case VariantTypePoolVector2Array:
+		var values []Vector2
+		err := Unmarshal(variant, &values)
+		return values, err
+	case VariantTypePoolVector3Array:
+		var values []Vector3
+		err := Unmarshal(variant, &values)
+		return values, err
+	case VariantTypePoolColorArray:
+		var values []Color
+		err := Unmarshal(variant, &values)
+		return values, err
+	}
+
+	// any other Godot type is returned as it is
+	var godotValue reflect.Value
+	switch variant.GetType() {
+	case VariantTypeVector2:
+		godotValue = reflect.ValueOf(variant.AsVector2())
+	case VariantTypeVector3:
+		godotValue = reflect.ValueOf(variant.AsVector3())
+	case VariantTypeRect2:
+		godotValue = reflect.ValueOf(variant.AsRect2())
+	case VariantTypeTransform2D:
+		godotValue = reflect.ValueOf(variant.AsTransform2D())
+	case VariantTypePlane:
+		godotValue = reflect.ValueOf(variant.AsPlane())
+	case VariantTypeQuat:
+		godotValue = reflect.ValueOf(variant.AsQuat())
+	case VariantTypeAabb:
+		godotValue = reflect.ValueOf(variant.AsAabb())
+	case VariantTypeBasis:
+		godotValue = reflect.ValueOf(variant.AsBasis())
+	case VariantTypeTransform:
+		godotValue = reflect.ValueOf(variant.AsTransform())
+	case VariantTypeColor:
+		godotValue = reflect.ValueOf(variant.AsColor())
+	case VariantTypeNodePath:
+		godotValue = reflect.ValueOf(variant.AsNodePath())
+	case VariantTypeRid:
+		godotValue = reflect.ValueOf(variant.AsRid())
+	case VariantTypeObject:
+		godotValue = reflect.ValueOf(variant.AsObject())
+	default:
+		return nil, &UnmarshalTypeError{VariantType: variant.GetType(), Type: reflect.TypeOf((*interface{})(nil)).Elem()}
+	}
+
+	return godotValue.Interface(), nil
+}
+
+// naturalDictionary converts a Dictionary into a map[string]interface{} or a
+// map[interface{}]interface{} if any of its keys is not a string
+func naturalDictionary(variant Variant) (interface{}, error) {
+
+	var values map[interface{}]interface{}
+	if err := Unmarshal(variant, &values); err != nil {
+		return nil, err
+	}
+
+	stringKeys := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		name, ok := key.(string)
+		if !ok {
+			return values, nil
+		}
+		stringKeys[name] = value
+	}
+
+	return stringKeys, nil
+}
+
+// fieldInfo describes a struct field for Marshal and Unmarshal
+type fieldInfo struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// fieldsCache caches the fields of every struct type used with Marshal or
+// Unmarshal, it is keyed by reflect.Type
+var fieldsCache sync.Map
+
+// structFields returns back the fields of the given struct type, fields of
+// embedded structs without a name tag are promoted like encoding/json does
+// while embedded gdnative types without a name tag are skipped
+func structFields(structType reflect.Type) []fieldInfo {
+
+	if cached, ok := fieldsCache.Load(structType); ok {
+		return cached.([]fieldInfo)
+	}
+
+	fields := []fieldInfo{}
+	seen := map[string]bool{}
+	collectFields(structType, nil, &fields, seen)
+
+	cached, _ := fieldsCache.LoadOrStore(structType, fields)
+	return cached.([]fieldInfo)
+}
+
+// collectFields appends the fields of the given struct type to fields, names
+// that are already present (from outer structs) take precedence
+func collectFields(structType reflect.Type, index []int, fields *[]fieldInfo, seen map[string]bool) {
+
+	embedded := [][]int{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get("godot")
+		if tag == "-" {
+			continue
+		}
+
+		name, options := tag, ""
+		if comma := strings.Index(tag, ","); comma >= 0 {
+			name, options = tag[:comma], tag[comma+1:]
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+
+		// promote the fields of embedded structs without a name tag
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			// embedded gdnative helpers like Owned or InstanceContext are not data
+			if !isGodotType(fieldType) {
+				embedded = append(embedded, fieldIndex)
+			}
+			continue
+		}
+
+		if field.PkgPath != "" {
+			// unexported field
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		*fields = append(*fields, fieldInfo{
+			name:      name,
+			index:     fieldIndex,
+			omitEmpty: strings.Contains(","+options+",", ",omitempty,"),
+		})
+	}
+
+	for _, fieldIndex := range embedded {
+		fieldType := structType.Field(fieldIndex[len(fieldIndex)-1]).Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		collectFields(fieldType, fieldIndex, fields, seen)
+	}
+}
+
+// isGodotType returns true if the given type is one of our Godot wrappers,
+// they are converted as a whole instead of field by field
+func isGodotType(typ reflect.Type) bool {
+	return typ.PkgPath() == variantType.PkgPath() && typ != timeType
+}
@@ -0,0 +1,160 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+//go:build fakeapi
+
+package gdnative
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// taggedInt marshals itself as text so it can not be stored in a pool
+type taggedInt int16
+
+func (i taggedInt) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("#%d", int16(i))), nil
+}
+
+func (i *taggedInt) UnmarshalText(text []byte) error {
+	_, err := fmt.Sscanf(string(text), "#%d", (*int16)(i))
+	return err
+}
+
+// upperString marshals itself as an upper case String Variant
+type upperString string
+
+func (s upperString) MarshalVariant() (Variant, error) {
+	return NewVariantWithString(String(strings.ToUpper(string(s)))), nil
+}
+
+func (s *upperString) UnmarshalVariant(variant Variant) error {
+	*s = upperString(strings.ToLower(string(variant.AsString())))
+	return nil
+}
+
+// doubledByte only implements VariantUnmarshaler, it doubles the stored value
+type doubledByte uint8
+
+func (b *doubledByte) UnmarshalVariant(variant Variant) error {
+	*b = doubledByte(variant.AsInt() * 2)
+	return nil
+}
+
+func TestMarshalSequenceElementTypes(t *testing.T) {
+	checkLeaks(t)
+
+	tests := []struct {
+		name  string
+		value interface{}
+		kind  VariantType
+	}{
+		{"int8", []int8{math.MinInt8, math.MaxInt8}, VariantTypePoolIntArray},
+		{"int16", []int16{math.MinInt16, math.MaxInt16}, VariantTypePoolIntArray},
+		{"int32", []int32{math.MinInt32, math.MaxInt32}, VariantTypePoolIntArray},
+		{"uint16", []uint16{0, math.MaxUint16}, VariantTypePoolIntArray},
+		{"float32", []float32{0.5, -1.25}, VariantTypePoolRealArray},
+		{"int", []int{math.MinInt32 - 1, math.MaxInt32 + 1}, VariantTypeArray},
+		{"int64", []int64{math.MinInt64, math.MaxInt64}, VariantTypeArray},
+		{"uint32", []uint32{0, math.MaxUint32}, VariantTypeArray},
+		{"float64", []float64{0.1, math.MaxFloat64}, VariantTypeArray},
+		{"int array", [2]int{1 << 40, -1 << 40}, VariantTypeArray},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			variant, err := Marshal(test.value)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			defer variant.Destroy()
+
+			if kind := variant.GetType(); kind != test.kind {
+				t.Errorf("Marshal type = %s; want %s", variantTypeName(kind), variantTypeName(test.kind))
+			}
+
+			result := reflect.New(reflect.TypeOf(test.value))
+			if err := Unmarshal(variant, result.Interface()); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got := result.Elem().Interface(); !reflect.DeepEqual(got, test.value) {
+				t.Errorf("round trip = %v; want %v", got, test.value)
+			}
+		})
+	}
+}
+
+func TestMarshalSequenceElementMarshalers(t *testing.T) {
+	checkLeaks(t)
+
+	tests := []struct {
+		name  string
+		value interface{}
+		first string
+	}{
+		{"text marshaler", []taggedInt{1, -2}, "#1"},
+		{"variant marshaler", []upperString{"go", "dot"}, "GO"},
+		{"variant marshaler array", [2]upperString{"a", "b"}, "A"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			variant, err := Marshal(test.value)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			defer variant.Destroy()
+
+			if kind := variant.GetType(); kind != VariantTypeArray {
+				t.Fatalf("Marshal type = %s; want Array", variantTypeName(kind))
+			}
+
+			array := variant.AsArray()
+			first := array.Get(0)
+			if got := string(first.AsString()); got != test.first {
+				t.Errorf("first element = %q; want %q", got, test.first)
+			}
+			first.Destroy()
+			array.Destroy()
+
+			result := reflect.New(reflect.TypeOf(test.value))
+			if err := Unmarshal(variant, result.Interface()); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got := result.Elem().Interface(); !reflect.DeepEqual(got, test.value) {
+				t.Errorf("round trip = %v; want %v", got, test.value)
+			}
+		})
+	}
+}
+
+func TestUnmarshalPoolArrayElementUnmarshaler(t *testing.T) {
+	checkLeaks(t)
+
+	variant := NewVariantBytes([]byte{1, 2, 3})
+	defer variant.Destroy()
+
+	var values []doubledByte
+	if err := Unmarshal(variant, &values); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if want := []doubledByte{2, 4, 6}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Unmarshal = %v; want %v", values, want)
+	}
+}
@@ -82,3 +82,25 @@ godot_signal_argument *go_godot_new_signal_argument() {
 	godot_signal_argument *arg = malloc(sizeof(godot_signal_argument));
 	return arg;
 }
+
+// Walks the NativeScript extension versions chain looking for the 1.1 API,
+// returns NULL if the running Godot version does not provide it.
+const godot_gdnative_ext_nativescript_1_1_api_struct *go_godot_nativescript_1_1_api(
+	const godot_gdnative_ext_nativescript_api_struct *api) {
+	const godot_gdnative_api_struct *next = api->next;
+	while (next != NULL) {
+		if (next->version.major == 1 && next->version.minor == 1) {
+			return (const godot_gdnative_ext_nativescript_1_1_api_struct *)next;
+		}
+		next = next->next;
+	}
+
+	return NULL;
+}
+
+// Adds profiling data for the given signature to Godot's script profiler.
+void go_godot_nativescript_profiling_add_data(
+	const godot_gdnative_ext_nativescript_1_1_api_struct *api, const char *signature,
+	uint64_t time) {
+	api->godot_nativescript_profiling_add_data(signature, time);
+}
@@ -27,7 +27,9 @@ package gdnative
 import "C"
 
 import (
+	"fmt"
 	"log"
+	"time"
 	"unsafe"
 )
 
@@ -95,7 +97,7 @@ type CreateFunc func(Object, string) string
 // CreateFuncRegistry is a mapping of instance creation functions. This map is
 // used whenever a CreateFunc is registered. It is also used to look up a
 // Creation function when Godot asks Go to create a new class instance.
-var CreateFuncRegistry = map[string]CreateFunc{}
+var CreateFuncRegistry = new(CreateFuncMap)
 
 // DestroyFunc will be called when the object is destroyed. Takes the instance
 // object, method data, user data. The method data is generally the class name,
@@ -105,7 +107,7 @@ type DestroyFunc func(Object, string, string)
 // DestroyFuncRegistry is a mapping of instance destroy functions. This map is
 // used whenever a DestroyFunc is registered. It is also used to look up a
 // Destroy function when Godot asks Go to destroy a class instance.
-var DestroyFuncRegistry = map[string]DestroyFunc{}
+var DestroyFuncRegistry = new(DestroyFuncMap)
 
 // FreeFunc will be called when we should free the instance from memory. Takes
 // in method data. The method data is generally the class name to be freed.
@@ -121,7 +123,7 @@ type SetPropertyFunc func(Object, string, string, Variant)
 // SetPropertyFuncRegistry is a mapping of instance property setters. This map is
 // used whenever a SetPropertyFunc is registered. It is also used to look up
 // a property setter function when Godot asks Go to set a property on an object.
-var SetPropertyFuncRegistry = map[string]SetPropertyFunc{}
+var SetPropertyFuncRegistry = new(SetPropertyFuncMap)
 
 // GetPropertyFunc will be called when Godot requests a property on a given class
 // instance. When it is called, Godot will pass the Godot object instance as an
@@ -133,12 +135,12 @@ type GetPropertyFunc func(Object, string, string) Variant
 // GetPropertyFuncRegistry is a mapping of instance property getters. This map is
 // used whenever a GetPropertyFunc is registered. It is also used to look up a
 // property getter function when Godot asks Go to get a property on an object.
-var GetPropertyFuncRegistry = map[string]GetPropertyFunc{}
+var GetPropertyFuncRegistry = new(GetPropertyFuncMap)
 
 // FreeFuncRegistry is a mapping of instance free functions. This map is used
 // whenever a FreeFunc is registered. It is also used to look up a Free
 // function when Godot asks Go to free a class instance.
-var FreeFuncRegistry = map[string]FreeFunc{}
+var FreeFuncRegistry = new(FreeFuncMap)
 
 // MethodFunc will be called when a method attached to an instance is called.
 // When it is called, it will be passed the Godot object the method is attached to,
@@ -151,7 +153,7 @@ type MethodFunc func(Object, string, string, int, []Variant) Variant
 // MethodFuncRegistry is a mapping of instance method functions. This map is
 // used whenever a MethodFunc is registered. It is also used to look up a Method
 // function when Godot asks Go to call a class method.
-var MethodFuncRegistry = map[string]MethodFunc{}
+var MethodFuncRegistry = new(MethodFuncMap)
 
 // InstanceCreateFunc is a structure that contains the instance creation function
 // that will be called when Godot asks Go to create a new instance of a class.
@@ -236,12 +238,33 @@ var NativeScript = new(nativeScript)
 type nativeScript struct {
 	api *C.godot_gdnative_ext_nativescript_api_struct
 
+	// api11 is the NativeScript 1.1 API, it is nil if the running Godot
+	// version does not provide it.
+	api11 *C.godot_gdnative_ext_nativescript_1_1_api_struct
+
 	// Handle is a pointer to the gdnative handler. It must be passed to any
 	// Godot nativescript functions. This will be populated when 'godot_nativescript_init'
 	// is called by Godot upon script initialization.
 	handle unsafe.Pointer
 }
 
+// InstanceIDOf returns back the instance ID (the userData string returned by
+// the class constructor) of the Go instance attached to the given Godot object,
+// it returns false if the object has no NativeScript instance attached to it
+func InstanceIDOf(object Object) (string, bool) {
+
+	if NativeScript.api == nil || object.base == nil {
+		return "", false
+	}
+
+	userData := C.go_godot_nativescript_get_userdata(NativeScript.api, object.base)
+	if userData == nil {
+		return "", false
+	}
+
+	return unsafeToGoString(userData), true
+}
+
 // RegisterClass will register the given class with Godot. This will make it
 // available to be attached to a Node in Godot. The name of the class that you
 // provide here will be the name that you specify when you attach a NativeScript
@@ -265,12 +288,13 @@ func (n *nativeScript) RegisterClass(name, base string, createFunc *InstanceCrea
 
 	// Register our Create and Destroy functions in a Go map, so the correct
 	// function can be called when cgo_gateway_<type>_func is called.
-	CreateFuncRegistry[createFunc.MethodData] = createFunc.CreateFunc
-	FreeFuncRegistry[createFunc.MethodData] = createFunc.FreeFunc
-	DestroyFuncRegistry[destroyFunc.MethodData] = destroyFunc.DestroyFunc
-	FreeFuncRegistry[destroyFunc.MethodData] = destroyFunc.FreeFunc
+	CreateFuncRegistry.Set(createFunc.MethodData, createFunc.CreateFunc)
+	FreeFuncRegistry.Set(createFunc.MethodData, createFunc.FreeFunc)
+	DestroyFuncRegistry.Set(destroyFunc.MethodData, destroyFunc.DestroyFunc)
+	FreeFuncRegistry.Set(destroyFunc.MethodData, destroyFunc.FreeFunc)
 
 	// Register the class with Godot.
+	registeredClasses.add(name, base)
 	C.go_godot_nativescript_register_class(
 		n.api,
 		n.handle,
@@ -294,10 +318,10 @@ func (n *nativeScript) RegisterToolClass(name, base string, createFunc *Instance
 
 	// Register our Create and Destroy functions in a Go map, so the correct
 	// function can be called when cgo_gateway_<type>_func is called.
-	CreateFuncRegistry[createFunc.MethodData] = createFunc.CreateFunc
-	FreeFuncRegistry[createFunc.MethodData] = createFunc.FreeFunc
-	DestroyFuncRegistry[destroyFunc.MethodData] = destroyFunc.DestroyFunc
-	FreeFuncRegistry[destroyFunc.MethodData] = destroyFunc.FreeFunc
+	CreateFuncRegistry.Set(createFunc.MethodData, createFunc.CreateFunc)
+	FreeFuncRegistry.Set(createFunc.MethodData, createFunc.FreeFunc)
+	DestroyFuncRegistry.Set(destroyFunc.MethodData, destroyFunc.DestroyFunc)
+	FreeFuncRegistry.Set(destroyFunc.MethodData, destroyFunc.FreeFunc)
 
 	// Register the class with Godot.
 	C.go_godot_nativescript_register_tool_class(
@@ -324,8 +348,8 @@ func (n *nativeScript) RegisterMethod(name, funcName string, attributes *MethodA
 
 	// Register the Method function in a Go map, so the correct function can
 	// be called when cgo_gateway_<type>_func is called.
-	MethodFuncRegistry[method.MethodData] = method.Method
-	FreeFuncRegistry[method.MethodData] = method.FreeFunc
+	MethodFuncRegistry.Set(method.MethodData, method.Method)
+	FreeFuncRegistry.Set(method.MethodData, method.FreeFunc)
 
 	// Register the method with Godot.
 	C.go_godot_nativescript_register_method(
@@ -367,10 +391,10 @@ func (n *nativeScript) RegisterProperty(name, path string, attributes *PropertyA
 
 	// Register the set/get property functions in a Go map, so the correct function can
 	// be called when cgo_gateway_<type>_func is called.
-	SetPropertyFuncRegistry[setFunc.MethodData] = setFunc.SetFunc
-	GetPropertyFuncRegistry[getFunc.MethodData] = getFunc.GetFunc
-	FreeFuncRegistry[setFunc.MethodData] = setFunc.FreeFunc
-	FreeFuncRegistry[getFunc.MethodData] = getFunc.FreeFunc
+	SetPropertyFuncRegistry.Set(setFunc.MethodData, setFunc.SetFunc)
+	GetPropertyFuncRegistry.Set(getFunc.MethodData, getFunc.GetFunc)
+	FreeFuncRegistry.Set(setFunc.MethodData, setFunc.FreeFunc)
+	FreeFuncRegistry.Set(getFunc.MethodData, getFunc.FreeFunc)
 
 	// Register the property with Godot.
 	C.go_godot_nativescript_register_property(
@@ -382,6 +406,9 @@ func (n *nativeScript) RegisterProperty(name, path string, attributes *PropertyA
 		setFunc.getBase(),
 		getFunc.getBase(),
 	)
+
+	// Godot keeps its own copy of the hint string
+	C.go_godot_string_destroy(GDNative.api, &attr.hint_string)
 }
 
 // RegisterSignal will register the given signal with Godot.
@@ -423,6 +450,16 @@ func (n *nativeScript) RegisterSignal(name string, signal *Signal) {
 		C.CString(name),
 		signal.getBase(),
 	)
+
+	// Godot keeps its own copies of the signal and arguments names
+	C.go_godot_string_destroy(GDNative.api, &base.name)
+	for _, cArg := range argsArray {
+		if cArg == nil {
+			continue
+		}
+		C.go_godot_string_destroy(GDNative.api, &cArg.name)
+		C.go_godot_string_destroy(GDNative.api, &cArg.hint_string)
+	}
 }
 
 // nativeScriptInit will be called when `godot_nativescript_init` is called by
@@ -458,6 +495,11 @@ func godot_nativescript_init(hdl unsafe.Pointer) {
 	}
 	NativeScript.handle = hdl
 
+	// NativeScript is initialized from Godot's main thread, remember it so
+	// RunOnMainSync can tell whether it has to wait for the dispatcher or not.
+	setMainThread()
+	registerMainThreadDispatcher()
+
 	// Call the user-defined nativeScriptInit function
 	if nativeScriptInit == nil {
 		err := "NativeScript initialization function was not set! Use `gdnative.SetNativeScriptInit` to define the function that will run to register classes."
@@ -471,6 +513,24 @@ func godot_nativescript_init(hdl unsafe.Pointer) {
 	for _, init := range nativeScriptInit {
 		init()
 	}
+
+	// Every class is registered now, add them to the crash session file.
+	updateCrashSession()
+}
+
+/** Script termination **/
+// godot_nativescript_terminate is called by Godot when NativeScript is
+// terminated, before the library itself is unloaded. It runs the hooks
+// registered with OnNativeScriptTerminate and clears our registries.
+//export godot_nativescript_terminate
+func godot_nativescript_terminate(hdl unsafe.Pointer) {
+	if debug {
+		log.Println("Terminating NativeScript")
+	}
+
+	nativeScriptTerminateHooks.run("nativescript terminate")
+	clearRegistries()
+	NativeScript.handle = nil
 }
 
 // This is a native Go function that is callable from C. It is called by the
@@ -479,16 +539,35 @@ func godot_nativescript_init(hdl unsafe.Pointer) {
 // CreateFunc. We will need to return UserData, which can be used to track the
 // actual instance that was created.
 //export go_create_func
-func go_create_func(godotObject *C.godot_object, methodData unsafe.Pointer) unsafe.Pointer {
+func go_create_func(godotObject *C.godot_object, methodData unsafe.Pointer) (instance unsafe.Pointer) {
 	// Convert the method data into a Go string.
 	methodDataString := unsafeToGoString(methodData)
 	if debug {
 		log.Println("Create function called for:", methodDataString)
 	}
 
+	// Keep track of the call for crash reports.
+	recentCalls.record("constructor", methodDataString)
+
+	// Recover from any panic raised by the constructor, Godot will get a nil
+	// user data back meaning the instance could not be created.
+	defer func() {
+		if strict.Load() {
+			return
+		}
+		if r := recover(); r != nil {
+			reportBoundaryPanic(r, "constructor", methodDataString)
+			instance = nil
+		}
+	}()
+
 	// Look up the creation function in our CreateFuncRegistry for the function
 	// to call.
-	constructor := CreateFuncRegistry[methodDataString]
+	constructor, ok := CreateFuncRegistry.Get(methodDataString)
+	if !ok {
+		Log.Error(fmt.Sprintf("could not find a create function for %s", methodDataString))
+		return nil
+	}
 
 	// Call the constructor and return the user data string. The user data
 	// returned by the create func will be passed to the method function as
@@ -512,9 +591,26 @@ func go_destroy_func(godotObject *C.godot_object, methodData unsafe.Pointer, use
 		log.Println("Destroy function called for:", methodDataString)
 	}
 
+	// Keep track of the call for crash reports.
+	recentCalls.record("destructor", methodDataString)
+
+	// Recover from any panic raised by the destructor.
+	defer func() {
+		if strict.Load() {
+			return
+		}
+		if r := recover(); r != nil {
+			reportBoundaryPanic(r, "destructor", methodDataString)
+		}
+	}()
+
 	// Look up the destroy function in our DestroyFuncRegistry for the function
 	// to call.
-	destructor := DestroyFuncRegistry[methodDataString]
+	destructor, ok := DestroyFuncRegistry.Get(methodDataString)
+	if !ok {
+		Log.Error(fmt.Sprintf("could not find a destroy function for %s", methodDataString))
+		return
+	}
 
 	// Call the destructor function. We pass the methodData and userData to
 	// the destructor so it knows which class and instance to destroy.
@@ -529,9 +625,25 @@ func go_free_func(methodData unsafe.Pointer) {
 		log.Println("Free function called for:", methodDataString)
 	}
 
+	// Keep track of the call for crash reports.
+	recentCalls.record("free function", methodDataString)
+
+	// Recover from any panic raised by the free function.
+	defer func() {
+		if strict.Load() {
+			return
+		}
+		if r := recover(); r != nil {
+			reportBoundaryPanic(r, "free function", methodDataString)
+		}
+	}()
+
 	// Look up the free function in our FreeFuncRegistry for the function
 	// to call.
-	freer := FreeFuncRegistry[methodDataString]
+	freer, ok := FreeFuncRegistry.Get(methodDataString)
+	if !ok {
+		return
+	}
 
 	// Call the free function. We pass the methodData to the free
 	// function so it knows which class to free.
@@ -541,11 +653,25 @@ func go_free_func(methodData unsafe.Pointer) {
 // This is a native Go function that is callable from C. It is called by the
 // gateway functions defined in nativescript.c.
 //export go_method_func
-func go_method_func(godotObject *C.godot_object, methodData unsafe.Pointer, userData unsafe.Pointer, numArgs C.int, args **C.godot_variant) C.godot_variant {
+func go_method_func(godotObject *C.godot_object, methodData unsafe.Pointer, userData unsafe.Pointer, numArgs C.int, args **C.godot_variant) (result C.godot_variant) {
 	// Convert the method data and user data into a Go string
 	methodDataString := unsafeToGoString(methodData)
 	userDataString := unsafeToGoString(userData)
 
+	// Keep track of the call for crash reports.
+	recentCalls.record("method", methodDataString)
+
+	// Recover from any panic raised by the method and return nil to Godot.
+	defer func() {
+		if strict.Load() {
+			return
+		}
+		if r := recover(); r != nil {
+			reportBoundaryPanic(r, "method", methodDataString)
+			result = handOverVariant(NewVariantNil())
+		}
+	}()
+
 	// Create a slice of Variants for the arguments
 	variantArgs := []Variant{}
 
@@ -565,8 +691,9 @@ func go_method_func(godotObject *C.godot_object, methodData unsafe.Pointer, user
 		arg := args
 		// Loop through all our arguments.
 		for i := 0; i < int(numArgs); i++ {
-			// Convert the variant into a Go Variant
-			variant := Variant{base: *arg}
+			// Wrap the variant, it belongs to Godot and is only valid
+			// during this call
+			variant := borrowedVariant(*arg)
 
 			// Append the variant to our list of variants
 			variantArgs = append(variantArgs, variant)
@@ -582,12 +709,24 @@ func go_method_func(godotObject *C.godot_object, methodData unsafe.Pointer, user
 
 	// Look up the method function in our MethodFuncRegistry for the function
 	// to call.
-	method := MethodFuncRegistry[methodDataString]
+	method, ok := MethodFuncRegistry.Get(methodDataString)
+	if !ok {
+		Log.Error(fmt.Sprintf("could not find a method function for %s", methodDataString))
+		return handOverVariant(NewVariantNil())
+	}
+
+	// Call the method, timing it for Godot's profiler if profiling is enabled
+	if profilingEnabled() {
+		start := time.Now()
+		ret := method(Object{base: godotObject}, methodDataString, userDataString, int(numArgs), variantArgs)
+		profilingAddData(methodData, time.Since(start))
+
+		return handOverVariant(ret)
+	}
 
-	// Call the method
 	ret := method(Object{base: godotObject}, methodDataString, userDataString, int(numArgs), variantArgs)
 
-	return *ret.getBase()
+	return handOverVariant(ret)
 }
 
 // This is a native Go function that is callable from C. It is called by the
@@ -598,12 +737,30 @@ func go_set_property_func(godotObject *C.godot_object, methodData unsafe.Pointer
 	methodDataString := unsafeToGoString(methodData)
 	userDataString := unsafeToGoString(userData)
 
-	// Convert the property into a Go variant
-	variant := Variant{base: property}
+	// Keep track of the call for crash reports.
+	recentCalls.record("property setter", methodDataString)
+
+	// Recover from any panic raised by the property setter.
+	defer func() {
+		if strict.Load() {
+			return
+		}
+		if r := recover(); r != nil {
+			reportBoundaryPanic(r, "property setter", methodDataString)
+		}
+	}()
+
+	// Convert the property into a Go variant, it belongs to Godot and is
+	// only valid during this call
+	variant := borrowedVariant(property)
 
 	// Look up the set property function in our SetPropertyFuncRegistry for
 	// the function to call.
-	setFunc := SetPropertyFuncRegistry[methodDataString]
+	setFunc, ok := SetPropertyFuncRegistry.Get(methodDataString)
+	if !ok {
+		Log.Error(fmt.Sprintf("could not find a property setter function for %s", methodDataString))
+		return
+	}
 
 	// Call the method
 	setFunc(Object{base: godotObject}, methodDataString, userDataString, variant)
@@ -612,17 +769,35 @@ func go_set_property_func(godotObject *C.godot_object, methodData unsafe.Pointer
 // This is a native Go function that is callable from C. It is called by the
 // gateway functions defined in nativescript.c.
 //export go_get_property_func
-func go_get_property_func(godotObject *C.godot_object, methodData unsafe.Pointer, userData unsafe.Pointer) C.godot_variant {
+func go_get_property_func(godotObject *C.godot_object, methodData unsafe.Pointer, userData unsafe.Pointer) (result C.godot_variant) {
 	// Convert the method data and user data into a Go string
 	methodDataString := unsafeToGoString(methodData)
 	userDataString := unsafeToGoString(userData)
 
+	// Keep track of the call for crash reports.
+	recentCalls.record("property getter", methodDataString)
+
+	// Recover from any panic raised by the property getter and return nil to Godot.
+	defer func() {
+		if strict.Load() {
+			return
+		}
+		if r := recover(); r != nil {
+			reportBoundaryPanic(r, "property getter", methodDataString)
+			result = handOverVariant(NewVariantNil())
+		}
+	}()
+
 	// Look up the get property function in our GetPropertyFuncRegistry for
 	// the function to call.
-	getFunc := GetPropertyFuncRegistry[methodDataString]
+	getFunc, ok := GetPropertyFuncRegistry.Get(methodDataString)
+	if !ok {
+		Log.Error(fmt.Sprintf("could not find a property getter function for %s", methodDataString))
+		return handOverVariant(NewVariantNil())
+	}
 
 	// Call the method
 	ret := getFunc(Object{base: godotObject}, methodDataString, userDataString)
 
-	return *ret.getBase()
+	return handOverVariant(ret)
 }
@@ -2,7 +2,9 @@
 #define CGDNATIVE_NATIVESCRIPT_GATEWAY_H
 
 #include <gdnative/gdnative.h>
+#include <gdnative_api_struct.gen.h>
 #include <nativescript/godot_nativescript.h>
+#include <stdint.h>
 
 /* GDNative NATIVESCRIPT C Gateway */
 void *cgo_gateway_create_func(godot_object *obj, void *method_data);
@@ -24,4 +26,11 @@ typedef void (*set_property_func)(godot_object *, void *, void *, godot_variant
 typedef godot_variant (*get_property_func)(godot_object *, void *, void *);
 
 godot_signal_argument *go_godot_new_signal_argument();
+
+/* NativeScript 1.1 API */
+const godot_gdnative_ext_nativescript_1_1_api_struct *go_godot_nativescript_1_1_api(
+	const godot_gdnative_ext_nativescript_api_struct *api);
+void go_godot_nativescript_profiling_add_data(
+	const godot_gdnative_ext_nativescript_1_1_api_struct *api, const char *signature,
+	uint64_t time);
 #endif
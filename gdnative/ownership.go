@@ -0,0 +1,285 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+/*
+#include <gdnative/variant.h>
+#include "gdnative.gen.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Ownership of Godot builtins
+//
+// Variant, Array, Dictionary, NodePath and Pool*Array values hold engine
+// memory. Values created from Go, by a constructor, an As* conversion, a
+// Godot API call or a Call, are owned by the caller that must release them
+// with Free (or Destroy) exactly once when it is done with them. Copies of a
+// value share the same engine memory so only one of them must be freed.
+//
+// Values that Godot passes into exported methods and property setters are
+// borrowed, they are only valid during the call and Free does nothing on
+// them. Values converted from them (for example with AsArray) are owned by
+// the method.
+//
+// Variants returned from exported methods (generated or registered by hand
+// with NewGodotMethod) are handed over to Godot which takes ownership of
+// them, Godot gets a copy of borrowed arguments instead. Arrays,
+// Dictionaries and other builtins returned from the exported methods of
+// generated classes are wrapped into a Variant and freed. A method that
+// returns a value it keeps stored must return a copy of it (NewVariantCopy,
+// NewArrayCopy...) so the stored value stays valid. Property getters of
+// generated classes give Godot a copy of the stored property value.
+//
+// String is a Go string and never needs to be freed, the temporary engine
+// strings built from it are destroyed by the package.
+//
+// SetAutoFree and SetLeakReporting make the package track the owned values
+// created from then on with finalizers. Finalizers are best effort, the Go
+// garbage collector gives no guarantee about when (or if) they run.
+
+var (
+	autoFree      int32
+	leakReporting int32
+)
+
+// SetAutoFree enables or disables finalizer based cleanup, when enabled the
+// owned builtins that become unreachable without being freed are destroyed
+// on Godot's main thread through RunOnMain. RunOnMain adds its dispatcher
+// node to the SceneTree when needed, until it can (for example before the
+// main loop exists) the values are only destroyed once it does and a warning
+// is logged
+func SetAutoFree(enabled bool) {
+	atomic.StoreInt32(&autoFree, boolToInt32(enabled))
+}
+
+// SetLeakReporting enables or disables the reporting of owned builtins that
+// are never freed. When enabled, the allocation stack of every owned value
+// is recorded, a warning is logged for values garbage collected without
+// being freed and the values still alive are listed when the library is
+// unloaded. It is meant for debugging as it makes allocations expensive
+func SetLeakReporting(enabled bool) {
+	atomic.StoreInt32(&leakReporting, boolToInt32(enabled))
+}
+
+// LiveBuiltins returns back the number of tracked builtins that have not
+// been freed yet by kind, only values created while SetAutoFree or
+// SetLeakReporting were enabled are tracked
+func LiveBuiltins() map[string]int {
+	return builtins.count()
+}
+
+func boolToInt32(value bool) int32 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+func trackingEnabled() bool {
+	return atomic.LoadInt32(&autoFree) == 1 || atomic.LoadInt32(&leakReporting) == 1
+}
+
+// builtinRecord describes a tracked owned builtin
+type builtinRecord struct {
+	kind  string
+	stack []uintptr
+	// clear removes the finalizer of the tracked value, it does not capture
+	// the value itself so the record does not keep it alive
+	clear func(unsafe.Pointer)
+}
+
+// origin returns back the location where the builtin was created
+func (r builtinRecord) origin() string {
+
+	if len(r.stack) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	frames := runtime.CallersFrames(r.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&builder, "\n    %s\n        %s:%d", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+
+	return builder.String()
+}
+
+// builtinRegistry keeps track of the owned builtins created from Go, values
+// are keyed by address so the registry does not keep them alive
+type builtinRegistry struct {
+	mutex sync.Mutex
+	owned map[uintptr]builtinRecord
+}
+
+var builtins = &builtinRegistry{
+	owned: map[uintptr]builtinRecord{},
+}
+
+func (r *builtinRegistry) add(address uintptr, record builtinRecord) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.owned[address] = record
+}
+
+func (r *builtinRegistry) remove(address uintptr) (builtinRecord, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	record, ok := r.owned[address]
+	if ok {
+		delete(r.owned, address)
+	}
+
+	return record, ok
+}
+
+func (r *builtinRegistry) count() map[string]int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	counts := map[string]int{}
+	for _, record := range r.owned {
+		counts[record.kind]++
+	}
+
+	return counts
+}
+
+// trackBuiltin starts tracking the given owned builtin when SetAutoFree or
+// SetLeakReporting are enabled, destroy is called from the main thread if
+// the value is garbage collected without being freed and auto free is on
+func trackBuiltin[T any](base *T, kind string, destroy func(*T)) {
+
+	if !trackingEnabled() {
+		return
+	}
+
+	record := builtinRecord{
+		kind: kind,
+		clear: func(pointer unsafe.Pointer) {
+			runtime.SetFinalizer((*T)(pointer), nil)
+		},
+	}
+
+	if atomic.LoadInt32(&leakReporting) == 1 {
+		stack := make([]uintptr, 16)
+		// skip runtime.Callers, trackBuiltin and the newOwned wrapper
+		record.stack = stack[:runtime.Callers(3, stack)]
+	}
+
+	builtins.add(uintptr(unsafe.Pointer(base)), record)
+	runtime.SetFinalizer(base, func(base *T) {
+		finalizeBuiltin(base, destroy)
+	})
+}
+
+// finalizeBuiltin is the finalizer of tracked builtins
+func finalizeBuiltin[T any](base *T, destroy func(*T)) {
+
+	record, ok := builtins.remove(uintptr(unsafe.Pointer(base)))
+	if !ok {
+		return
+	}
+
+	if atomic.LoadInt32(&leakReporting) == 1 {
+		Log.Warning(fmt.Sprintf("gdnative: %s garbage collected without being freed, created at:%s", record.kind, record.origin()))
+	}
+
+	if atomic.LoadInt32(&autoFree) == 1 {
+		RunOnMain(func() {
+			if GDNative.IsInitialized() {
+				destroy(base)
+			}
+		})
+	}
+}
+
+// untrackBuiltin stops tracking the given builtin, it is called when the
+// builtin is destroyed or handed over to Godot
+func untrackBuiltin(pointer unsafe.Pointer) {
+
+	record, ok := builtins.remove(uintptr(pointer))
+	if ok {
+		record.clear(pointer)
+	}
+}
+
+// borrowedVariant wraps the given godot_variant passed in by Godot, it is
+// only valid during the current call and Free does nothing on it
+func borrowedVariant(base *C.godot_variant) Variant {
+	return Variant{base: base, borrowed: true}
+}
+
+// handOverVariant returns back the contents of the given Variant so they
+// can be returned to Godot, that takes ownership of them. Owned values are
+// moved, borrowed values still belong to Godot so it gets a copy of them
+func handOverVariant(variant Variant) C.godot_variant {
+
+	var ret C.godot_variant
+	switch {
+	case variant.base == nil:
+		C.go_godot_variant_new_nil(GDNative.api, &ret)
+	case variant.borrowed:
+		C.go_godot_variant_new_copy(GDNative.api, &ret, variant.base)
+	default:
+		untrackBuiltin(unsafe.Pointer(variant.base))
+		ret = *variant.base
+	}
+
+	return ret
+}
+
+// reportLiveBuiltins logs the tracked builtins that were never freed when
+// leak reporting is enabled, it is called when the library is unloaded
+func reportLiveBuiltins() {
+
+	if atomic.LoadInt32(&leakReporting) == 0 {
+		return
+	}
+
+	builtins.mutex.Lock()
+	records := make([]builtinRecord, 0, len(builtins.owned))
+	for _, record := range builtins.owned {
+		records = append(records, record)
+	}
+	builtins.mutex.Unlock()
+
+	if len(records) == 0 {
+		return
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].kind < records[j].kind })
+	for _, record := range records {
+		Log.Warning(fmt.Sprintf("gdnative: %s was never freed, created at:%s", record.kind, record.origin()))
+	}
+	Log.Warning(fmt.Sprintf("gdnative: %d builtins were never freed", len(records)))
+}
@@ -0,0 +1,66 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+//go:build fakeapi
+
+package gdnative
+
+import "testing"
+
+// newArrayVariant returns an owned Variant holding a new empty Array
+func newArrayVariant() Variant {
+	array := NewArray()
+	defer array.Destroy()
+	return NewVariantArray(array)
+}
+
+func TestHandOverOwnedVariant(t *testing.T) {
+	checkLeaks(t)
+
+	owned := newArrayVariant()
+	live := fakeLiveObjects()
+
+	// the owned Variant is moved, Godot frees the returned value
+	ret := handOverVariant(owned)
+	if got := fakeLiveObjects(); got != live {
+		t.Errorf("live objects after hand over = %d; want %d", got, live)
+	}
+
+	returned := newOwnedVariant(&ret)
+	returned.Destroy()
+}
+
+func TestHandOverBorrowedVariant(t *testing.T) {
+	checkLeaks(t)
+
+	godot := newArrayVariant()
+	defer godot.Destroy()
+
+	borrowed := borrowedVariant(godot.getBase())
+	live := fakeLiveObjects()
+	borrowed.Free()
+	if got := fakeLiveObjects(); got != live {
+		t.Errorf("live objects after freeing a borrowed Variant = %d; want %d", got, live)
+	}
+
+	// the borrowed Variant still belongs to Godot, it gets a copy
+	ret := handOverVariant(borrowed)
+	returned := newOwnedVariant(&ret)
+	returned.Destroy()
+
+	if godot.GetType() != VariantTypeArray {
+		t.Errorf("borrowed Variant type = %v; want Array", godot.GetType())
+	}
+}
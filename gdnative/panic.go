@@ -0,0 +1,66 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+)
+
+// strict determines whether or not panics raised by Go code called from Godot
+// are recovered at the Godot to Go boundary, it is read from every thread that
+// calls into Go
+var strict atomic.Bool
+
+// EnableStrictMode disables panic recovery at the Godot to Go boundary. Any
+// panic raised inside a method, property getter/setter, constructor or
+// destructor will unwind through the cgo gateway and crash Godot (and the
+// editor with it). This is useful when debugging with a native debugger.
+func EnableStrictMode() {
+	strict.Store(true)
+}
+
+// reportBoundaryPanic logs a recovered panic through Log.Error including the
+// Go stack trace and the class and method names that raised it. It must be
+// called from the deferred function that recovered the panic so the stack
+// trace still contains the frames that panicked.
+func reportBoundaryPanic(recovered interface{}, kind, methodData string) {
+
+	className, methodName := splitMethodData(methodData)
+	location := fmt.Sprintf("class %s", className)
+	if methodName != "" {
+		location = fmt.Sprintf("%s %s %s", location, kind, methodName)
+	} else {
+		location = fmt.Sprintf("%s %s", location, kind)
+	}
+
+	Log.Error(fmt.Sprintf("recovered from panic in %s: %v\n%s", location, recovered, debug.Stack()))
+}
+
+// splitMethodData splits method data in the form of "Class::method" into its
+// class and method names, method data that only contains a class name returns
+// an empty method name
+func splitMethodData(methodData string) (string, string) {
+
+	parts := strings.SplitN(methodData, "::", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
@@ -21,17 +21,49 @@ import (
 	"strings"
 )
 
-// instances is a map of our created Godot classes. This will be
+// instances is a registry of our created Godot classes. This will be
 // populated when Godot calls the CreateFunc
-var instances = map[string]*Class{}
+var instances = NewInstanceRegistry()
 
 // Objectable is the interface every Godot Object has to implement
 type Objectable interface {
+	Ownable
 	BaseClass() string
+}
+
+// Ownable is implemented by registered types that want to know the Godot
+// object that owns them. The generated constructor calls SetOwner with the
+// Godot object right after the value is created so methods can reach their
+// own Node (to call methods on it or to emit signals for example)
+type Ownable interface {
 	SetOwner(Object)
 	Owner() Object
 }
 
+// Owned implements Ownable, it can be embedded into any registered type to
+// have its owner Godot object injected when it is created by Godot:
+//
+//	type Player struct {
+//		gdnative.Owned
+//	}
+//
+//	func (p *Player) Heal() {
+//		p.Owner().Call("play_animation", gdnative.NewVariantWithString("heal"))
+//	}
+type Owned struct {
+	owner Object
+}
+
+// SetOwner sets the Godot object that owns this value
+func (o *Owned) SetOwner(owner Object) {
+	o.owner = owner
+}
+
+// Owner returns back the Godot object that owns this value
+func (o *Owned) Owner() Object {
+	return o.owner
+}
+
 // GDSignal is a NativeScript registrable signal
 type GDSignal struct {
 	name       string
@@ -149,7 +181,7 @@ func (c *Class) createGenericConstructor() *InstanceCreateFunc {
 		Log.Println(fmt.Sprintf("Creating Go generic class %s(%s) constructor with ID %s", c.name, c.base, id))
 
 		// use the class pointer address as the instance ID
-		instances[id] = c
+		instances.Set(id, c)
 
 		return id
 	}
@@ -164,7 +196,7 @@ func (c *Class) createGenericDestructor() *InstanceDestroyFunc {
 	destructorFunc := func(object Object, methodData, userData string) {
 
 		Log.Println(fmt.Sprintf("Destroying %s value with ID: %s", c.name, userData))
-		delete(instances, userData)
+		instances.Delete(userData)
 	}
 
 	destroyFunc := CreateDestructor(c.name, destructorFunc)
@@ -208,7 +240,7 @@ func NewGodotMethod(className, name string, method MethodFunc) Method {
 		},
 		&InstanceMethod{
 			Method:     method,
-			MethodData: name,
+			MethodData: fmt.Sprintf("%s::%s", className, name),
 			FreeFunc:   func(methodData string) {},
 		},
 	}
@@ -360,6 +392,13 @@ func (p *Property) SetGetter(getter *InstancePropertyGet) {
 	p.getFunc = getter
 }
 
+// WithType sets the VariantType Godot uses for this property in the inspector
+// and returns the Property back so it can be chained with NewGodotProperty
+func (p Property) WithType(kind VariantType) Property {
+	p.attributes.Type = Int(kind)
+	return p
+}
+
 // GetName returns back the property name
 func (p *Property) GetName() string {
 	return p.propertyName
@@ -0,0 +1,240 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+/*
+#include <gdnative/pool_arrays.h>
+#include "gdnative.gen.h"
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// The helpers on this file copy Pool*Array contents from and into Go slices
+// using a single copy while holding Godot's read or write lock, it avoids a
+// cgo call per element that the generated Append and Get methods need
+
+// NewPoolRealArrayFromFloats creates a new PoolRealArray holding a copy of the given Real values
+func NewPoolRealArrayFromFloats(values []float32) PoolRealArray {
+
+	array := NewPoolRealArray()
+	if len(values) == 0 {
+		return array
+	}
+
+	C.go_godot_pool_real_array_resize(GDNative.api, array.getBase(), C.godot_int(len(values)))
+	access := C.go_godot_pool_real_array_write(GDNative.api, array.getBase())
+	defer C.go_godot_pool_real_array_write_access_destroy(GDNative.api, access)
+
+	ptr := C.go_godot_pool_real_array_write_access_ptr(GDNative.api, access)
+	copy(unsafe.Slice((*float32)(unsafe.Pointer(ptr)), len(values)), values)
+
+	return array
+}
+
+// Floats returns back a copy of the contents of this PoolRealArray
+func (gdt *PoolRealArray) Floats() []float32 {
+
+	size := int(C.go_godot_pool_real_array_size(GDNative.api, gdt.getBase()))
+	values := make([]float32, size)
+	if size == 0 {
+		return values
+	}
+
+	access := C.go_godot_pool_real_array_read(GDNative.api, gdt.getBase())
+	defer C.go_godot_pool_real_array_read_access_destroy(GDNative.api, access)
+
+	ptr := C.go_godot_pool_real_array_read_access_ptr(GDNative.api, access)
+	copy(values, unsafe.Slice((*float32)(unsafe.Pointer(ptr)), size))
+
+	return values
+}
+
+// NewPoolIntArrayFromInts creates a new PoolIntArray holding a copy of the given 32 bits integers
+func NewPoolIntArrayFromInts(values []int32) PoolIntArray {
+
+	array := NewPoolIntArray()
+	if len(values) == 0 {
+		return array
+	}
+
+	C.go_godot_pool_int_array_resize(GDNative.api, array.getBase(), C.godot_int(len(values)))
+	access := C.go_godot_pool_int_array_write(GDNative.api, array.getBase())
+	defer C.go_godot_pool_int_array_write_access_destroy(GDNative.api, access)
+
+	ptr := C.go_godot_pool_int_array_write_access_ptr(GDNative.api, access)
+	copy(unsafe.Slice((*int32)(unsafe.Pointer(ptr)), len(values)), values)
+
+	return array
+}
+
+// Ints returns back a copy of the contents of this PoolIntArray
+func (gdt *PoolIntArray) Ints() []int32 {
+
+	size := int(C.go_godot_pool_int_array_size(GDNative.api, gdt.getBase()))
+	values := make([]int32, size)
+	if size == 0 {
+		return values
+	}
+
+	access := C.go_godot_pool_int_array_read(GDNative.api, gdt.getBase())
+	defer C.go_godot_pool_int_array_read_access_destroy(GDNative.api, access)
+
+	ptr := C.go_godot_pool_int_array_read_access_ptr(GDNative.api, access)
+	copy(values, unsafe.Slice((*int32)(unsafe.Pointer(ptr)), size))
+
+	return values
+}
+
+// NewPoolVector2ArrayFromVector2s creates a new PoolVector2Array holding a copy of the given values
+func NewPoolVector2ArrayFromVector2s(values []Vector2) PoolVector2Array {
+
+	array := NewPoolVector2Array()
+	if len(values) == 0 {
+		return array
+	}
+
+	C.go_godot_pool_vector2_array_resize(GDNative.api, array.getBase(), C.godot_int(len(values)))
+	access := C.go_godot_pool_vector2_array_write(GDNative.api, array.getBase())
+	defer C.go_godot_pool_vector2_array_write_access_destroy(GDNative.api, access)
+
+	ptr := C.go_godot_pool_vector2_array_write_access_ptr(GDNative.api, access)
+	buffer := unsafe.Slice((*C.godot_vector2)(unsafe.Pointer(ptr)), len(values))
+	for i, value := range values {
+		if value.base != nil {
+			buffer[i] = *value.base
+		}
+	}
+
+	return array
+}
+
+// Vector2s returns back a copy of the contents of this PoolVector2Array, the returned
+// values share a single Go allocation
+func (gdt *PoolVector2Array) Vector2s() []Vector2 {
+
+	size := int(C.go_godot_pool_vector2_array_size(GDNative.api, gdt.getBase()))
+	values := make([]Vector2, size)
+	if size == 0 {
+		return values
+	}
+
+	buffer := make([]C.godot_vector2, size)
+	access := C.go_godot_pool_vector2_array_read(GDNative.api, gdt.getBase())
+	ptr := C.go_godot_pool_vector2_array_read_access_ptr(GDNative.api, access)
+	copy(buffer, unsafe.Slice((*C.godot_vector2)(unsafe.Pointer(ptr)), size))
+	C.go_godot_pool_vector2_array_read_access_destroy(GDNative.api, access)
+
+	for i := range buffer {
+		values[i] = Vector2{base: &buffer[i]}
+	}
+
+	return values
+}
+
+// NewPoolVector3ArrayFromVector3s creates a new PoolVector3Array holding a copy of the given values
+func NewPoolVector3ArrayFromVector3s(values []Vector3) PoolVector3Array {
+
+	array := NewPoolVector3Array()
+	if len(values) == 0 {
+		return array
+	}
+
+	C.go_godot_pool_vector3_array_resize(GDNative.api, array.getBase(), C.godot_int(len(values)))
+	access := C.go_godot_pool_vector3_array_write(GDNative.api, array.getBase())
+	defer C.go_godot_pool_vector3_array_write_access_destroy(GDNative.api, access)
+
+	ptr := C.go_godot_pool_vector3_array_write_access_ptr(GDNative.api, access)
+	buffer := unsafe.Slice((*C.godot_vector3)(unsafe.Pointer(ptr)), len(values))
+	for i, value := range values {
+		if value.base != nil {
+			buffer[i] = *value.base
+		}
+	}
+
+	return array
+}
+
+// Vector3s returns back a copy of the contents of this PoolVector3Array, the returned
+// values share a single Go allocation
+func (gdt *PoolVector3Array) Vector3s() []Vector3 {
+
+	size := int(C.go_godot_pool_vector3_array_size(GDNative.api, gdt.getBase()))
+	values := make([]Vector3, size)
+	if size == 0 {
+		return values
+	}
+
+	buffer := make([]C.godot_vector3, size)
+	access := C.go_godot_pool_vector3_array_read(GDNative.api, gdt.getBase())
+	ptr := C.go_godot_pool_vector3_array_read_access_ptr(GDNative.api, access)
+	copy(buffer, unsafe.Slice((*C.godot_vector3)(unsafe.Pointer(ptr)), size))
+	C.go_godot_pool_vector3_array_read_access_destroy(GDNative.api, access)
+
+	for i := range buffer {
+		values[i] = Vector3{base: &buffer[i]}
+	}
+
+	return values
+}
+
+// NewPoolColorArrayFromColors creates a new PoolColorArray holding a copy of the given values
+func NewPoolColorArrayFromColors(values []Color) PoolColorArray {
+
+	array := NewPoolColorArray()
+	if len(values) == 0 {
+		return array
+	}
+
+	C.go_godot_pool_color_array_resize(GDNative.api, array.getBase(), C.godot_int(len(values)))
+	access := C.go_godot_pool_color_array_write(GDNative.api, array.getBase())
+	defer C.go_godot_pool_color_array_write_access_destroy(GDNative.api, access)
+
+	ptr := C.go_godot_pool_color_array_write_access_ptr(GDNative.api, access)
+	buffer := unsafe.Slice((*C.godot_color)(unsafe.Pointer(ptr)), len(values))
+	for i, value := range values {
+		if value.base != nil {
+			buffer[i] = *value.base
+		}
+	}
+
+	return array
+}
+
+// Colors returns back a copy of the contents of this PoolColorArray, the returned
+// values share a single Go allocation
+func (gdt *PoolColorArray) Colors() []Color {
+
+	size := int(C.go_godot_pool_color_array_size(GDNative.api, gdt.getBase()))
+	values := make([]Color, size)
+	if size == 0 {
+		return values
+	}
+
+	buffer := make([]C.godot_color, size)
+	access := C.go_godot_pool_color_array_read(GDNative.api, gdt.getBase())
+	ptr := C.go_godot_pool_color_array_read_access_ptr(GDNative.api, access)
+	copy(buffer, unsafe.Slice((*C.godot_color)(unsafe.Pointer(ptr)), size))
+	C.go_godot_pool_color_array_read_access_destroy(GDNative.api, access)
+
+	for i := range buffer {
+		values[i] = Color{base: &buffer[i]}
+	}
+
+	return values
+}
@@ -0,0 +1,147 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+//go:build fakeapi
+
+package gdnative
+
+import "testing"
+
+// poolBenchmarkSize is the number of elements the pool array benchmarks
+// copy, the bulk helpers copy them at once while the generated methods need
+// a cgo call per element. The benchmarks run against the fake core API of the
+// fakeapi build tag, not a running Godot, so they measure the cgo calls and
+// copies on our side but not the cost of Godot's own pool implementation
+const poolBenchmarkSize = 4096
+
+func BenchmarkPoolByteArrayWrite(b *testing.B) {
+
+	values := make([]byte, poolBenchmarkSize)
+	b.Run("bulk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pool := NewPoolByteArrayFromBytes(values)
+			pool.Destroy()
+		}
+	})
+	b.Run("append", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pool := NewPoolByteArray()
+			for _, value := range values {
+				pool.Append(Uint8T(value))
+			}
+			pool.Destroy()
+		}
+	})
+}
+
+func BenchmarkPoolByteArrayRead(b *testing.B) {
+
+	pool := NewPoolByteArrayFromBytes(make([]byte, poolBenchmarkSize))
+	defer pool.Destroy()
+
+	b.Run("bulk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pool.Bytes()
+		}
+	})
+	b.Run("get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			values := make([]byte, poolBenchmarkSize)
+			for j := range values {
+				values[j] = byte(pool.Get(Int(j)))
+			}
+		}
+	})
+}
+
+func BenchmarkPoolIntArrayWrite(b *testing.B) {
+
+	values := make([]int32, poolBenchmarkSize)
+	b.Run("bulk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pool := NewPoolIntArrayFromInts(values)
+			pool.Destroy()
+		}
+	})
+	b.Run("append", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pool := NewPoolIntArray()
+			for _, value := range values {
+				pool.Append(Int(value))
+			}
+			pool.Destroy()
+		}
+	})
+}
+
+func BenchmarkPoolIntArrayRead(b *testing.B) {
+
+	pool := NewPoolIntArrayFromInts(make([]int32, poolBenchmarkSize))
+	defer pool.Destroy()
+
+	b.Run("bulk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pool.Ints()
+		}
+	})
+	b.Run("get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			values := make([]int32, poolBenchmarkSize)
+			for j := range values {
+				values[j] = int32(pool.Get(Int(j)))
+			}
+		}
+	})
+}
+
+func BenchmarkPoolRealArrayWrite(b *testing.B) {
+
+	values := make([]float32, poolBenchmarkSize)
+	b.Run("bulk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pool := NewPoolRealArrayFromFloats(values)
+			pool.Destroy()
+		}
+	})
+	b.Run("append", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pool := NewPoolRealArray()
+			for _, value := range values {
+				pool.Append(Real(value))
+			}
+			pool.Destroy()
+		}
+	})
+}
+
+func BenchmarkPoolRealArrayRead(b *testing.B) {
+
+	pool := NewPoolRealArrayFromFloats(make([]float32, poolBenchmarkSize))
+	defer pool.Destroy()
+
+	b.Run("bulk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pool.Floats()
+		}
+	})
+	b.Run("get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			values := make([]float32, poolBenchmarkSize)
+			for j := range values {
+				values[j] = float32(pool.Get(Int(j)))
+			}
+		}
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+/*
+#include <gdnative/pool_arrays.h>
+#include "gdnative.gen.h"
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// NewPoolByteArrayFromBytes creates a new PoolByteArray holding a copy of the
+// given bytes, the contents are copied with a single memcpy
+func NewPoolByteArrayFromBytes(data []byte) PoolByteArray {
+
+	array := NewPoolByteArray()
+	if len(data) == 0 {
+		return array
+	}
+
+	C.go_godot_pool_byte_array_resize(GDNative.api, array.getBase(), C.godot_int(len(data)))
+	array.WithBytes(func(buffer []byte) {
+		copy(buffer, data)
+	})
+
+	return array
+}
+
+// NewVariantBytes creates a new Variant holding a PoolByteArray with a copy of the given bytes
+func NewVariantBytes(data []byte) Variant {
+
+	array := NewPoolByteArrayFromBytes(data)
+	defer array.Destroy()
+
+	return NewVariantPoolByteArray(array)
+}
+
+// AsBytes returns back a copy of the bytes held by this Variant, Arrays are
+// converted into a PoolByteArray by Godot first
+func (gdt *Variant) AsBytes() []byte {
+
+	array := gdt.AsPoolByteArray()
+	defer array.Destroy()
+
+	return array.Bytes()
+}
+
+// Bytes returns back a copy of the contents of this PoolByteArray, the
+// contents are copied with a single memcpy while holding a read lock
+func (gdt *PoolByteArray) Bytes() []byte {
+
+	var data []byte
+	gdt.ReadBytes(func(buffer []byte) {
+		data = make([]byte, len(buffer))
+		copy(data, buffer)
+	})
+
+	return data
+}
+
+// ReadBytes calls the given function with a read only view over the contents
+// of this PoolByteArray while holding a read lock on it. The slice points
+// directly to Godot's memory so it must not be modified or retained after
+// the function returns
+func (gdt *PoolByteArray) ReadBytes(fn func([]byte)) {
+
+	size := int(C.go_godot_pool_byte_array_size(GDNative.api, gdt.getBase()))
+	if size == 0 {
+		fn([]byte{})
+		return
+	}
+
+	access := C.go_godot_pool_byte_array_read(GDNative.api, gdt.getBase())
+	defer C.go_godot_pool_byte_array_read_access_destroy(GDNative.api, access)
+
+	ptr := C.go_godot_pool_byte_array_read_access_ptr(GDNative.api, access)
+	fn(unsafe.Slice((*byte)(unsafe.Pointer(ptr)), size))
+}
+
+// WithBytes calls the given function with a writable view over the contents
+// of this PoolByteArray while holding a write lock on it. The slice points
+// directly to Godot's memory so it must not be retained after the function
+// returns, use Resize first to change the size of the array
+func (gdt *PoolByteArray) WithBytes(fn func([]byte)) {
+
+	size := int(C.go_godot_pool_byte_array_size(GDNative.api, gdt.getBase()))
+	if size == 0 {
+		fn([]byte{})
+		return
+	}
+
+	access := C.go_godot_pool_byte_array_write(GDNative.api, gdt.getBase())
+	defer C.go_godot_pool_byte_array_write_access_destroy(GDNative.api, access)
+
+	ptr := C.go_godot_pool_byte_array_write_access_ptr(GDNative.api, access)
+	fn(unsafe.Slice((*byte)(unsafe.Pointer(ptr)), size))
+}
@@ -0,0 +1,58 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+/*
+#include "nativescript.h"
+*/
+import "C"
+
+import (
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// profiling determines whether or not Go method calls are timed and reported
+// to Godot's script profiler, it is accessed atomically
+var profiling int32
+
+// EnableProfiling makes every Go method called by Godot to be timed and
+// reported to Godot's script profiler under its "Class::method" signature,
+// so Go code shows up next to GDScript in the editor profiler. It requires
+// the NativeScript 1.1 API (Godot 3.1 or later), it does nothing otherwise.
+func EnableProfiling() {
+	atomic.StoreInt32(&profiling, 1)
+}
+
+// DisableProfiling stops reporting Go method calls to Godot's script profiler
+func DisableProfiling() {
+	atomic.StoreInt32(&profiling, 0)
+}
+
+// profilingEnabled returns true if Go method calls have to be profiled
+func profilingEnabled() bool {
+	return atomic.LoadInt32(&profiling) == 1 && NativeScript.api11 != nil
+}
+
+// profilingAddData reports the time that it took to execute the method with
+// the given signature to Godot's script profiler. The signature is the C
+// string that Godot passes us as method data so no allocation is needed.
+func profilingAddData(signature unsafe.Pointer, elapsed time.Duration) {
+	C.go_godot_nativescript_profiling_add_data(
+		NativeScript.api11, (*C.char)(signature), C.uint64_t(elapsed.Microseconds()),
+	)
+}
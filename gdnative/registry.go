@@ -65,7 +65,11 @@ func (rc *registryClass) Alias() string {
 func (rc *registryClass) GetConstructor() string {
 
 	if rc.constructor != nil {
-		return fmt.Sprintf("func %s() *%s", rc.constructor.customFunc, rc.constructor.class)
+		params := ""
+		if rc.constructor.withContext {
+			params = "ctx context.Context"
+		}
+		return fmt.Sprintf("func %s(%s) *%s", rc.constructor.customFunc, params, rc.constructor.class)
 	}
 
 	return ""
@@ -94,6 +98,12 @@ func (rc *registryClass) Constructor() string {
 	return ""
 }
 
+// ConstructorTakesContext returns true if this type custom constructor takes
+// the instance context.Context as parameter
+func (rc *registryClass) ConstructorTakesContext() bool {
+	return rc.constructor != nil && rc.constructor.withContext
+}
+
 // GetDestructor returns back this type destructor as a string
 func (rc *registryClass) GetDestructor() string {
 
@@ -143,6 +153,18 @@ func (rc *registryClass) Methods() []*registryMethod {
 	return rc.methods
 }
 
+// HasMethod returns true if this type has a method with the given name
+func (rc *registryClass) HasMethod(name string) bool {
+
+	for _, method := range rc.methods {
+		if method.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 // AddMethods adds a list of methods for this type
 func (rc *registryClass) AddMethods(methods []*registryMethod) {
 
@@ -214,6 +236,7 @@ func (rc *registryClass) Properties() []*registryProperty {
 
 type registryConstructor struct {
 	class, customFunc string
+	withContext       bool
 }
 
 type registryDestructor struct {
@@ -224,6 +247,7 @@ type registryMethod struct {
 	class, name, alias string
 	params             []*registryMethodParam
 	returnValues       []*registryMethodReturnValue
+	async              bool
 }
 
 // GetName returns the method name
@@ -277,6 +301,33 @@ func (rm *registryMethod) HasReturns() bool {
 	return len(rm.returnValues) > 0
 }
 
+// HasValueReturns returns true if this method returns values other than an error
+func (rm *registryMethod) HasValueReturns() bool {
+	return len(rm.valueReturns()) > 0
+}
+
+// ReturnsError returns true if the last value returned by this method is an error
+func (rm *registryMethod) ReturnsError() bool {
+
+	length := len(rm.returnValues)
+	return length > 0 && rm.returnValues[length-1].kind == "error"
+}
+
+// Async returns true if this method has been exported with godot::export async
+func (rm *registryMethod) Async() bool {
+	return rm.async
+}
+
+// valueReturns returns this method return values without the trailing error
+func (rm *registryMethod) valueReturns() []*registryMethodReturnValue {
+
+	if rm.ReturnsError() {
+		return rm.returnValues[:len(rm.returnValues)-1]
+	}
+
+	return rm.returnValues
+}
+
 // FunctionCallWithParams returns a string representing how this method should be called
 func (rm *registryMethod) FunctionCallWithParams() string {
 
@@ -287,53 +338,56 @@ func (rm *registryMethod) FunctionCallWithParams() string {
 	return fmt.Sprintf("%s(%s)", rm.name, strings.Join(arguments, ", "))
 }
 
-// NewVariantType returns the right NewVariant<Type> method from gdnative for our return type
-func (rm *registryMethod) NewVariantType() string {
+// ReturnNames returns the names of the variables that hold this method value
+// returns (without the trailing error) in the generated code
+func (rm *registryMethod) ReturnNames() string {
 
-	variant := "NewVariant%s(gdnative.%s(value))"
-	conversions := map[string]string{
-		"bool":    fmt.Sprintf(variant, "Bool", "Bool"),
-		"uint":    fmt.Sprintf(variant, "Uint", "Uint64T"),
-		"int":     fmt.Sprintf(variant, "Int", "Int64T"),
-		"float64": fmt.Sprintf(variant, "Real", "Double"),
-		"string":  fmt.Sprintf(variant, "String", "String"),
+	returnValues := rm.valueReturns()
+	if len(returnValues) == 1 {
+		return "value"
+	}
+
+	names := make([]string, len(returnValues))
+	for i := range returnValues {
+		names[i] = fmt.Sprintf("value%d", i)
 	}
 
-	retLength := len(rm.returnValues)
+	return strings.Join(names, ", ")
+}
+
+// NewVariantType returns the Go expression that converts this method return
+// values into a gdnative.Variant
+func (rm *registryMethod) NewVariantType() string {
+
+	returnValues := rm.valueReturns()
+	retLength := len(returnValues)
 	if retLength == 1 {
-		result, ok := conversions[rm.returnValues[0].kind]
-		if !ok {
-			result = "value"
-		}
-		return result
+		return returnValues[0].toVariant("value", "methodData")
 	}
 
+	// two or three floats are returned as a Vector2 or Vector3
 	if retLength >= 2 && retLength <= 3 {
-		valid := make([]bool, retLength)
-		for i, val := range rm.returnValues {
+		allValid := true
+		reals := make([]string, retLength)
+		for i, val := range returnValues {
 			switch val.kind {
 			case "float32", "float64", "gdnative.Double", "gdnative.Real":
-				valid[i] = true
-			}
-		}
-		allValid := true
-		for _, b := range valid {
-			if !b {
+				reals[i] = fmt.Sprintf("gdnative.Real(value%d)", i)
+			default:
 				allValid = false
-				break
 			}
 		}
 
 		if allValid {
 			return fmt.Sprintf(
-				variant,
-				fmt.Sprintf("Vector%d", retLength),
-				fmt.Sprintf("NewVector%d", retLength),
+				"gdnative.NewVariantVector%d(gdnative.NewVector%d(%s))",
+				retLength, retLength, strings.Join(reals, ", "),
 			)
 		}
 	}
 
-	return "value"
+	// any other combination of values is returned as an Array
+	return fmt.Sprintf("marshalReturnValue(methodData, []interface{}{%s})", rm.ReturnNames())
 }
 
 type registryProperty struct {
@@ -350,6 +404,11 @@ func (rp *registryProperty) Kind() string {
 	return rp.gdnativeKind
 }
 
+// VariantType returns the gdnative.VariantType constant for this property
+func (rp *registryProperty) VariantType() string {
+	return rp.kind
+}
+
 // Alias returns the property alias back
 func (rp *registryProperty) Alias() string {
 	return rp.alias
@@ -375,26 +434,29 @@ func (rp *registryProperty) RsetType() string {
 	return rp.rset
 }
 
-// SetConvert writes right syntax for conversion from gdnative.Variant into Go type
+// GoType returns this property Go type as Go source code
+func (rp *registryProperty) GoType() string {
+	return goType(rp.gdnativeKind)
+}
+
+// SetConvert writes right syntax for conversion from gdnative.Variant into Go type,
+// it returns an empty string if the value has to be converted using gdnative.Unmarshal
 func (rp *registryProperty) SetConvert() string {
 
-	switch rp.gdnativeKind {
-	case "gdnative.Int":
-		return "gdnative.Int(property.AsInt())"
-	default:
-		return fmt.Sprintf("property.As%s()", strings.Replace(rp.gdnativeKind, "gdnative.", "", -1))
+	// the property Variant belongs to Godot so we keep a copy of it
+	if rp.gdnativeKind == "gdnative.Variant" {
+		return "gdnative.NewVariantCopy(property)"
 	}
+
+	param := registryMethodParam{name: rp.name, kind: rp.gdnativeKind}
+	return param.ConvertFunction("property")
 }
 
 // GetConvert writes right syntax for conversion from Go type into gdnative.Variant
 func (rp *registryProperty) GetConvert() string {
 
-	switch rp.gdnativeKind {
-	case "gdnative.Int":
-		return fmt.Sprintf("gdnative.NewVariant%s(gdnative.Int64T(class.class.%s))", rp.gdnativeKind[9:], rp.name)
-	default:
-		return fmt.Sprintf("gdnative.NewVariant%s(class.class.%s)", rp.gdnativeKind[9:], rp.name)
-	}
+	value := registryMethodReturnValue{kind: rp.gdnativeKind, stored: true}
+	return value.toVariant(fmt.Sprintf("class.class.%s", rp.name), "classProperty")
 }
 
 // SetFunc returns this property set function or default one
@@ -436,6 +498,8 @@ func (rs *registrySignal) Defaults() string {
 
 type registryMethodParam struct {
 	name, kind string
+	// class is the registered class name when this param is a pointer to it
+	class string
 }
 
 // Name returns this param name
@@ -448,115 +512,229 @@ func (rmp *registryMethodParam) Kind() string {
 	return rmp.kind
 }
 
-// ConvertFunction returns the right GDNative 'As<Type>' function for this param kind as a string
-func (rmp *registryMethodParam) ConvertFunction() string {
+// GoType returns this param kind as Go source code
+func (rmp *registryMethodParam) GoType() string {
+	return goType(rmp.kind)
+}
+
+// RegisteredClass returns the registered class name this param points to if any
+func (rmp *registryMethodParam) RegisteredClass() string {
+	return rmp.class
+}
+
+// BorrowsVariant returns true if this param is the Variant passed by Godot as
+// it is, it is only valid until the method returns
+func (rmp *registryMethodParam) BorrowsVariant() bool {
+	return rmp.class == "" && rmp.kind == "gdnative.Variant"
+}
+
+// IsTypedView returns true if this param is a TypedArray or TypedDictionary,
+// the view is over a new reference to the container passed by Godot that
+// has to be freed once the method returns
+func (rmp *registryMethodParam) IsTypedView() bool {
+	return strings.HasPrefix(rmp.kind, "gdnative.TypedArray[") || strings.HasPrefix(rmp.kind, "gdnative.TypedDictionary[")
+}
+
+// ConvertFunction returns the Go expression that converts the given source
+// gdnative.Variant into this param kind, it returns an empty string if the
+// value has to be converted using gdnative.Unmarshal instead
+func (rmp *registryMethodParam) ConvertFunction(source string) string {
 
 	conversions := map[string]string{
-		"bool":              "AsBool()",
-		"uint":              "AsUint()",
-		"int":               "AsInt()",
-		"float64":           "AsReal()",
-		"string":            "AsString()",
-		"vector2":           "AsVector2()",
-		"vector3":           "AsVector3()",
-		"rect2":             "AsRect2()",
-		"transform2d":       "AsTransform2D()",
-		"plane":             "AsPlane()",
-		"quat":              "AsQuat()",
-		"aabb":              "AsAabb()",
-		"basis":             "AsBasis()",
-		"transform":         "AsTransform()",
-		"color":             "AsColor()",
-		"nodepath":          "AsNodePath()",
-		"rid":               "AsRid()",
-		"object":            "AsObject()",
-		"dictionary":        "AsDictionary()",
-		"arraytype":         "AsArray()",
-		"arraytype_byte":    "AsPoolByteArray()",
-		"arraytype_int":     "AsPoolIntArray()",
-		"arraytype_float":   "AsPoolRealArray()",
-		"arraytype_string":  "AsPoolStringArray()",
-		"arraytype_vector2": "AsPoolVector2Array()",
-		"arraytype_vector3": "AsPoolVector3Array()",
-		"arraytype_color":   "AsPoolColorArray()",
-	}
-
-	value, ok := conversions[rmp.kind]
-	if ok {
-		return value
+		"bool":    "bool(%s.AsBool())",
+		"int":     "int(%s.AsInt())",
+		"int64":   "int64(%s.AsInt())",
+		"float64": "float64(%s.AsReal())",
+		"string":  "string(%s.AsString())",
+
+		"ArrayType[byte]":  "%s.AsBytes()",
+		"ArrayType[uint8]": "%s.AsBytes()",
+
+		"gdnative.Variant": "%s",
+		"gdnative.Bool":    "%s.AsBool()",
+		"gdnative.Int":     "gdnative.Int(%s.AsInt())",
+		"gdnative.Int64T":  "%s.AsInt()",
+		"gdnative.Uint64T": "%s.AsUint()",
+		"gdnative.Real":    "gdnative.Real(%s.AsReal())",
+		"gdnative.Double":  "%s.AsReal()",
+		"gdnative.String":  "%s.AsString()",
 	}
 
-	switch rmp.kind {
-	case "float32":
-		value = conversions["float64"]
-	case "int8", "int16", "int32", "int64", "byte":
-		value = conversions["int"]
-	case "uint8", "uint16", "uint32", "uint64":
-		value = conversions["uint"]
-	case "gdnative.Float", "gdnative.Double":
-		value = conversions["float64"]
-	case "gdnative.Int64T", "gdnative.SignedChar":
-		value = conversions["int"]
-	case "gdnative.Uint", "gdnative.Uint8T", "gdnative.Uint32T", "gdnative.Uint64T":
-		value = conversions["uint"]
-	case "gdnative.String", "gdnative.Char", "gdnative.WcharT":
-		value = conversions["string"]
-	default:
-		if strings.HasPrefix(rmp.kind, "gdnative.") {
-			value = rmp.kind
-		}
+	if conversion, ok := conversions[rmp.kind]; ok {
+		return fmt.Sprintf(conversion, source)
+	}
 
-		if strings.HasPrefix(rmp.kind, "godot.") || strings.HasPrefix(rmp.kind, "*godot.") {
-			value = conversions["object"]
-		}
+	if name, ok := variantBuiltin(rmp.kind); ok {
+		return fmt.Sprintf("%s.As%s()", source, name)
+	}
 
-		if strings.Contains(rmp.kind, "ArrayType") {
-			value = conversions[parseArrayType(rmp.kind)]
-		}
+	if strings.HasPrefix(rmp.kind, "godot.") || strings.HasPrefix(rmp.kind, "*godot.") {
+		return fmt.Sprintf("%s.AsObject()", source)
+	}
+
+	return ""
+}
+
+type registryMethodReturnValue struct {
+	kind string
+	// class is the registered class name when this value is a pointer to it
+	class string
+	// stored is true when the value is kept by the instance (a property) so
+	// Godot must get a copy of it instead of the value itself
+	stored bool
+}
+
+// toVariant returns the Go expression that converts the given Go variable of
+// this return value kind into a gdnative.Variant, methodData is the variable
+// that holds the name used to log conversion errors
+func (rv *registryMethodReturnValue) toVariant(value, methodData string) string {
 
-		if strings.Contains(rmp.kind, "MapType") {
-			value = conversions["dictionary"]
+	if rv.class != "" {
+		return fmt.Sprintf("variantFrom%s(%s)", rv.class, value)
+	}
+
+	conversions := map[string]string{
+		"bool":    "gdnative.NewVariantBool(gdnative.Bool(%s))",
+		"int":     "gdnative.NewVariantInt(gdnative.Int64T(%s))",
+		"int8":    "gdnative.NewVariantInt(gdnative.Int64T(%s))",
+		"int16":   "gdnative.NewVariantInt(gdnative.Int64T(%s))",
+		"int32":   "gdnative.NewVariantInt(gdnative.Int64T(%s))",
+		"int64":   "gdnative.NewVariantInt(gdnative.Int64T(%s))",
+		"uint8":   "gdnative.NewVariantInt(gdnative.Int64T(%s))",
+		"uint16":  "gdnative.NewVariantInt(gdnative.Int64T(%s))",
+		"uint32":  "gdnative.NewVariantInt(gdnative.Int64T(%s))",
+		"byte":    "gdnative.NewVariantInt(gdnative.Int64T(%s))",
+		"float32": "gdnative.NewVariantReal(gdnative.Double(%s))",
+		"float64": "gdnative.NewVariantReal(gdnative.Double(%s))",
+		"string":  "gdnative.NewVariantString(gdnative.String(%s))",
+
+		"ArrayType[byte]":  "gdnative.NewVariantBytes(%s)",
+		"ArrayType[uint8]": "gdnative.NewVariantBytes(%s)",
+
+		"gdnative.Bool":    "gdnative.NewVariantBool(%s)",
+		"gdnative.Int":     "gdnative.NewVariantInt(gdnative.Int64T(%s))",
+		"gdnative.Int64T":  "gdnative.NewVariantInt(%s)",
+		"gdnative.Uint64T": "gdnative.NewVariantUint(%s)",
+		"gdnative.Real":    "gdnative.NewVariantReal(gdnative.Double(%s))",
+		"gdnative.Double":  "gdnative.NewVariantReal(%s)",
+		"gdnative.String":  "gdnative.NewVariantString(%s)",
+	}
+
+	if conversion, ok := conversions[rv.kind]; ok {
+		return fmt.Sprintf(conversion, value)
+	}
+
+	// Variants returned by methods are handed over to Godot (that copies
+	// borrowed arguments), stored values are copied so the instance keeps
+	// its own
+	if rv.kind == "gdnative.Variant" {
+		if rv.stored {
+			return fmt.Sprintf("gdnative.NewVariantCopy(%s)", value)
 		}
+		return value
 	}
 
-	if value == "" {
-		value = conversions["object"]
+	// the Variant holds its own reference to the builtin so the one returned
+	// by the method is freed once wrapped
+	if name, ok := variantBuiltin(rv.kind); ok {
+		if ownedBuiltins[name] && !rv.stored {
+			return fmt.Sprintf("moveIntoVariant(%s, gdnative.NewVariant%s)", value, name)
+		}
+		return fmt.Sprintf("gdnative.NewVariant%s(%s)", name, value)
 	}
 
-	return value
+	return fmt.Sprintf("marshalReturnValue(%s, %s)", methodData, value)
 }
 
-type registryMethodReturnValue struct {
-	kind string
+// variantBuiltins are the gdnative types that a Variant can hold as they are
+var variantBuiltins = []string{
+	"Vector2", "Vector3", "Rect2", "Transform2D", "Plane", "Quat", "Aabb",
+	"Basis", "Transform", "Color", "NodePath", "Rid", "Object", "Dictionary",
+	"Array", "PoolByteArray", "PoolIntArray", "PoolRealArray", "PoolStringArray",
+	"PoolVector2Array", "PoolVector3Array", "PoolColorArray",
 }
 
-func parseArrayType(array string) string {
+// ownedBuiltins are the variantBuiltins holding engine memory that must be
+// freed, see Ownership of Godot builtins
+var ownedBuiltins = map[string]bool{
+	"NodePath": true, "Dictionary": true, "Array": true, "PoolByteArray": true,
+	"PoolIntArray": true, "PoolRealArray": true, "PoolStringArray": true,
+	"PoolVector2Array": true, "PoolVector3Array": true, "PoolColorArray": true,
+}
+
+// variantBuiltin returns the name of the gdnative type of the given kind if
+// a Variant can hold it as it is
+func variantBuiltin(kind string) (string, bool) {
+
+	if !strings.HasPrefix(kind, "gdnative.") {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(kind, "gdnative.")
+	for _, builtin := range variantBuiltins {
+		if name == builtin {
+			return name, true
+		}
+	}
 
-	var arrayType string
-	var openBracket bool
-	var openBracketCount int
-	for i := 0; i < len(array); i++ {
-		if openBracket {
-			if array[i] == ']' {
-				openBracketCount--
-				if openBracketCount == 0 {
-					openBracket = false
-					continue
-				}
+	return "", false
+}
+
+// goType converts a kind produced by parseDefault back into Go source code
+func goType(kind string) string {
+
+	switch {
+	case strings.HasPrefix(kind, "*"):
+		return "*" + goType(kind[1:])
+	case strings.HasPrefix(kind, "ArrayType["):
+		return "[]" + goType(kind[len("ArrayType["):len(kind)-1])
+	case strings.HasPrefix(kind, "MapType["):
+		rest := kind[len("MapType["):]
+		depth := 1
+		for i := 0; i < len(rest); i++ {
+			switch rest[i] {
+			case '[':
+				depth++
+			case ']':
+				depth--
 			}
 
-			if array[i] == '[' {
-				openBracketCount++
+			if depth == 0 {
+				return fmt.Sprintf("map[%s]%s", goType(rest[:i]), goType(rest[i+1:]))
 			}
-			arrayType += string(array[i])
-			continue
 		}
+	case strings.HasSuffix(kind, "]") && strings.Contains(kind, "["):
+		// instantiation of a generic type like gdnative.TypedArray[int64]
+		start := strings.Index(kind, "[")
+		args := splitTypeArgs(kind[start+1 : len(kind)-1])
+		for i, arg := range args {
+			args[i] = goType(arg)
+		}
+		return fmt.Sprintf("%s[%s]", kind[:start], strings.Join(args, ", "))
+	}
 
-		if array[i] == '[' {
-			openBracket = true
-			openBracketCount = 1
+	return kind
+}
+
+// splitTypeArgs splits the given comma separated list of type arguments
+// ignoring the commas nested in brackets
+func splitTypeArgs(list string) []string {
+
+	args := []string{}
+	depth, start := 0, 0
+	for i := 0; i < len(list); i++ {
+		switch list[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
 		}
 	}
 
-	return fmt.Sprintf("arraytype_%s", strings.ReplaceAll(arrayType, "gdnative.", ""))
+	return append(args, strings.TrimSpace(list[start:]))
 }
@@ -0,0 +1,108 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// propertiesSource declares a class with plain Go field types as properties,
+// only tagged fields are registered as properties
+const propertiesSource = "package player\n" +
+	"type Player struct {\n" +
+	"	Health int     `usage:\"default\"`\n" +
+	"	Level  int8    `usage:\"default\"`\n" +
+	"	Speed  float32 `usage:\"default\"`\n" +
+	"	Name   string  `usage:\"default\"`\n" +
+	"	Alive  bool    `usage:\"default\"`\n" +
+	"}\n"
+
+func TestPlainGoProperties(t *testing.T) {
+
+	file, err := parser.ParseFile(token.NewFileSet(), "player.go", propertiesSource, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("could not parse the source: %v", err)
+	}
+
+	properties := map[string]*registryProperty{}
+	for _, property := range lookupProperties("Player", file) {
+		properties[property.Name()] = property
+	}
+
+	// an empty setConvert means the generated setter uses gdnative.Unmarshal
+	// into a value of goType
+	tests := []struct {
+		name        string
+		variantType string
+		goType      string
+		setConvert  string
+		getConvert  string
+	}{
+		{
+			"Health", "gdnative.VariantTypeInt", "int",
+			"int(property.AsInt())",
+			"gdnative.NewVariantInt(gdnative.Int64T(class.class.Health))",
+		},
+		{
+			"Level", "gdnative.VariantTypeInt", "int8",
+			"",
+			"gdnative.NewVariantInt(gdnative.Int64T(class.class.Level))",
+		},
+		{
+			"Speed", "gdnative.VariantTypeReal", "float32",
+			"",
+			"gdnative.NewVariantReal(gdnative.Double(class.class.Speed))",
+		},
+		{
+			"Name", "gdnative.VariantTypeString", "string",
+			"string(property.AsString())",
+			"gdnative.NewVariantString(gdnative.String(class.class.Name))",
+		},
+		{
+			"Alive", "gdnative.VariantTypeBool", "bool",
+			"bool(property.AsBool())",
+			"gdnative.NewVariantBool(gdnative.Bool(class.class.Alive))",
+		},
+	}
+
+	if len(properties) != len(tests) {
+		t.Fatalf("found %d properties; want %d", len(properties), len(tests))
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			property, ok := properties[test.name]
+			if !ok {
+				t.Fatalf("property %s not found", test.name)
+			}
+
+			if got := property.VariantType(); got != test.variantType {
+				t.Errorf("VariantType = %s; want %s", got, test.variantType)
+			}
+			if got := property.GoType(); got != test.goType {
+				t.Errorf("GoType = %s; want %s", got, test.goType)
+			}
+			if got := property.SetConvert(); got != test.setConvert {
+				t.Errorf("SetConvert = %q; want %q", got, test.setConvert)
+			}
+			if got := property.GetConvert(); got != test.getConvert {
+				t.Errorf("GetConvert = %q; want %q", got, test.getConvert)
+			}
+		})
+	}
+}
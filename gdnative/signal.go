@@ -0,0 +1,54 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"fmt"
+	"sync"
+)
+
+// emitSignalBind caches the Object.emit_signal method bind, it is looked up
+// the first time that a signal is emitted
+var (
+	emitSignalOnce sync.Once
+	emitSignalBind MethodBind
+)
+
+// EmitSignal emits the signal with the given name and arguments on the given
+// Godot object, every connected GDScript (or native) listener is called
+// before EmitSignal returns. Like any other Godot API it must be called from
+// Godot's main thread, goroutines should wrap it with RunOnMain.
+func EmitSignal(owner Object, name string, args ...Variant) {
+	GDNative.checkInit()
+	if owner.getBase() == nil {
+		Log.Error(fmt.Sprintf("can not emit signal %s, Godot object pointer was nil", name))
+		return
+	}
+
+	emitSignalOnce.Do(func() {
+		emitSignalBind = NewMethodBind("Object", "emit_signal")
+	})
+
+	// emit_signal is a vararg method, its first argument is the signal name
+	signal := NewVariantWithString(String(name))
+	defer signal.Destroy()
+
+	result, err := emitSignalBind.Call(owner, append([]Variant{signal}, args...)...)
+	result.Destroy()
+	if err != nil {
+		Log.Error(fmt.Sprintf("could not emit signal %s: %s", name, err))
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+//go:build linux || darwin
+
+package gdnative
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// stdioQueueSize is the number of lines that can be waiting to be printed
+// in Godot, lines written while the queue is full are only written to the
+// original file descriptor
+const stdioQueueSize = 1024
+
+// stdio holds the state of the standard output and error redirection
+var stdio struct {
+	mu      sync.Mutex
+	streams []*redirectedStream
+	lines   chan string
+	done    chan struct{}
+	echoes  *stdioEchoes
+}
+
+// redirectedStream is a standard file descriptor redirected to a pipe
+type redirectedStream struct {
+	fd       int
+	original *os.File
+	reader   *os.File
+	writer   *os.File
+	done     chan struct{}
+}
+
+// stdioEchoes tracks the lines that we printed through Godot, when Godot
+// writes its own output to the standard output those lines come back to us
+// through the pipe and must not be forwarded again
+type stdioEchoes struct {
+	mu    sync.Mutex
+	lines map[string]int
+}
+
+// add records that the given line is going to be echoed
+func (e *stdioEchoes) add(line string) {
+	e.mu.Lock()
+	e.lines[line]++
+	e.mu.Unlock()
+}
+
+// take returns true if the given line is an echo of a line we printed
+func (e *stdioEchoes) take(line string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lines[line] == 0 {
+		return false
+	}
+
+	e.lines[line]--
+	if e.lines[line] == 0 {
+		delete(e.lines, line)
+	}
+
+	return true
+}
+
+// RedirectStdio redirects the process standard output and standard error
+// file descriptors into pipes, everything written to them (fmt.Println, log,
+// C printf calls...) is split into lines and printed in the Godot output panel
+// through a thread safe print path. The output is also written to the original
+// file descriptors so it is still visible in the terminal. The redirection is
+// undone when Godot unloads the library.
+//
+// The Go runtime writes the trace of a fatal error (an unrecovered panic, a
+// concurrent map write...) to the standard error right before the process
+// exits, the goroutine reading the pipe dies with it so the trace may be cut
+// or missing in the terminal. The crash reporter writes those traces into its
+// crash files through debug.SetCrashOutput, keep it enabled to not lose them.
+//
+// Godot writes its own output to the standard output and its errors to the
+// standard error too, unless the application/run/disable_stdout and
+// application/run/disable_stderr project settings are enabled Godot's own
+// lines show up twice in the output panel.
+// RedirectStdio must be called from Godot's main thread after GDNative has
+// been initialized, in an OnSingletonInit hook or a NativeScript init function
+// for example.
+func RedirectStdio() error {
+	if !GDNative.IsInitialized() {
+		return fmt.Errorf("can not redirect stdio before GDNative is initialized")
+	}
+
+	stdio.mu.Lock()
+	defer stdio.mu.Unlock()
+
+	if stdio.streams != nil {
+		return nil
+	}
+
+	// lines printed through Godot only come back to us if Godot writes its
+	// output to the standard output
+	if !godotProjectSetting("application/run/disable_stdout") {
+		Log.Warning("Godot writes its own output to stdout, enable application/run/disable_stdout to avoid duplicated lines")
+		stdio.echoes = &stdioEchoes{lines: map[string]int{}}
+	}
+	if !godotProjectSetting("application/run/disable_stderr") {
+		Log.Warning("Godot writes its own errors to stderr, enable application/run/disable_stderr to avoid duplicated lines")
+	}
+
+	stdio.lines = make(chan string, stdioQueueSize)
+	stdio.done = make(chan struct{})
+	go printStdioLines(stdio.lines, stdio.done, stdio.echoes)
+
+	for _, fd := range []int{syscall.Stdout, syscall.Stderr} {
+		stream, err := redirectStream(fd, stdio.lines, stdio.echoes)
+		if err != nil {
+			restoreStreams()
+			return fmt.Errorf("could not redirect file descriptor %d: %w", fd, err)
+		}
+		stdio.streams = append(stdio.streams, stream)
+	}
+
+	return nil
+}
+
+// restoreStdio restores the original standard output and error file
+// descriptors, it is called when Godot unloads the library
+func restoreStdio() {
+	stdio.mu.Lock()
+	defer stdio.mu.Unlock()
+
+	restoreStreams()
+}
+
+// restoreStreams restores the redirected streams and waits for the pending
+// lines to be printed, the stdio lock must be held by the caller
+func restoreStreams() {
+
+	for _, stream := range stdio.streams {
+		stream.restore()
+	}
+	stdio.streams = nil
+
+	if stdio.lines != nil {
+		close(stdio.lines)
+		<-stdio.done
+		stdio.lines = nil
+	}
+	stdio.echoes = nil
+}
+
+// redirectStream redirects the given file descriptor into a new pipe and
+// starts reading lines from it
+func redirectStream(fd int, lines chan<- string, echoes *stdioEchoes) (*redirectedStream, error) {
+
+	originalFD, err := syscall.Dup(fd)
+	if err != nil {
+		return nil, err
+	}
+	original := os.NewFile(uintptr(originalFD), fmt.Sprintf("original fd %d", fd))
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		original.Close()
+		return nil, err
+	}
+
+	if err := dup2(int(writer.Fd()), fd); err != nil {
+		original.Close()
+		reader.Close()
+		writer.Close()
+		return nil, err
+	}
+
+	stream := redirectedStream{fd: fd, original: original, reader: reader, writer: writer, done: make(chan struct{})}
+	go stream.read(lines, echoes)
+
+	return &stream, nil
+}
+
+// read reads lines from the pipe, writes them to the original file
+// descriptor and queues them to be printed in Godot. It never blocks on Godot
+// as Godot itself may be writing to the pipe we are reading from.
+func (s *redirectedStream) read(lines chan<- string, echoes *stdioEchoes) {
+	defer close(s.done)
+
+	buffer := bufio.NewReader(s.reader)
+	for {
+		line, err := buffer.ReadString('\n')
+		if line != "" {
+			text := strings.TrimRight(line, "\r\n")
+			if echoes != nil && echoes.take(text) {
+				// this is Godot printing a line we forwarded, the original
+				// line has been written to the terminal already
+				continue
+			}
+
+			s.original.WriteString(line)
+			select {
+			case lines <- text:
+			default:
+				// the queue is full, the line is only visible in the terminal
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// restore restores the original file descriptor and stops reading the pipe
+func (s *redirectedStream) restore() {
+
+	if err := dup2(int(s.original.Fd()), s.fd); err != nil {
+		fmt.Fprintf(s.original, "could not restore file descriptor %d: %s\n", s.fd, err)
+	}
+
+	// closing our writer closes the last reference to the pipe write end so
+	// the reader gets an EOF once the buffered output has been read
+	s.writer.Close()
+	<-s.done
+	s.reader.Close()
+	s.original.Close()
+}
+
+// printStdioLines prints the queued lines in Godot until the queue is closed
+func printStdioLines(lines <-chan string, done chan<- struct{}, echoes *stdioEchoes) {
+	defer close(done)
+
+	for line := range lines {
+		if !GDNative.IsInitialized() {
+			continue
+		}
+
+		if echoes != nil {
+			echoes.add(line)
+		}
+		godotPrint(line)
+	}
+}
+
+// godotProjectSetting returns the value of the given boolean project setting
+func godotProjectSetting(name string) bool {
+
+	setting := NewVariantWithString(String(name))
+	defer setting.Destroy()
+
+	value, err := GetSingleton("ProjectSettings").Call("get_setting", setting)
+	defer value.Destroy()
+	if err != nil {
+		return false
+	}
+
+	return bool(value.AsBool())
+}
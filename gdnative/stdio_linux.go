@@ -0,0 +1,24 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import "syscall"
+
+// dup2 makes newFD a copy of oldFD, some linux architectures (arm64) do not
+// provide the dup2 syscall so dup3 is used instead
+func dup2(oldFD, newFD int) error {
+	return syscall.Dup3(oldFD, newFD, 0)
+}
@@ -6,6 +6,12 @@ package gdnative
 */
 import "C"
 
+import (
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
+
 // NewStringWithWideString creates a new String with given contents
 func NewStringWithWideString(str string) String {
 	return String(str)
@@ -15,3 +21,46 @@ func NewStringWithWideString(str string) String {
 func NewString() String {
 	return ""
 }
+
+// stringAsGodotString returns a new godot_string holding the given UTF-8 Go
+// string, invalid UTF-8 sequences are replaced with U+FFFD. Godot stops
+// decoding at the first NUL byte so anything after an embedded NUL is lost.
+// The returned godot_string is owned by the caller that must destroy it with
+// go_godot_string_destroy once Godot is done with it (Godot copies strings
+// passed as arguments so that is usually right after the call)
+func stringAsGodotString(value string) *C.godot_string {
+	var godotString C.godot_string
+	if value == "" {
+		C.go_godot_string_new(GDNative.api, &godotString)
+		return &godotString
+	}
+
+	if !utf8.ValidString(value) {
+		value = strings.ToValidUTF8(value, string(utf8.RuneError))
+	}
+
+	// Godot copies the bytes so we can pass the Go string memory directly,
+	// it does not have to be NUL terminated as the length is given in bytes
+	godotString = C.go_godot_string_chars_to_utf8_with_len(
+		GDNative.api,
+		(*C.char)(unsafe.Pointer(unsafe.StringData(value))),
+		C.godot_int(len(value)),
+	)
+
+	return &godotString
+}
+
+// godotStringAsString returns back a Go string with the UTF-8 encoded
+// contents of the given godot_string, the godot_string is not destroyed
+func godotStringAsString(godotString *C.godot_string) string {
+	utfString := C.go_godot_string_utf8(GDNative.api, godotString)
+	defer C.go_godot_char_string_destroy(GDNative.api, &utfString)
+
+	length := C.go_godot_char_string_length(GDNative.api, &utfString)
+	if length == 0 {
+		return ""
+	}
+
+	data := C.go_godot_char_string_get_data(GDNative.api, &utfString)
+	return C.GoStringN(data, C.int(length))
+}
@@ -12,6 +12,7 @@ import "C"
 func NewStringName(name String) *StringName {
 	var dest C.godot_string_name
 	arg1 := name.getBase()
+	defer C.go_godot_string_destroy(GDNative.api, arg1)
 	C.go_godot_string_name_new(GDNative.api, &dest, arg1)
 	return &StringName{base: &dest}
 }
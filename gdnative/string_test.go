@@ -0,0 +1,66 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+//go:build fakeapi
+
+package gdnative
+
+import "testing"
+
+func TestStringRoundTrip(t *testing.T) {
+	checkLeaks(t)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   string
+		length int
+	}{
+		{"empty", "", "", 0},
+		{"ascii", "Godot", "Godot", 5},
+		{"latin", "ñandú", "ñandú", 5},
+		{"cjk", "日本語", "日本語", 3},
+		{"non BMP", "𝄞 clef", "𝄞 clef", 6},
+		{"emoji", "🎮👾", "🎮👾", 2},
+		{"mixed", "a€𐍈😀", "a€𐍈😀", 4},
+		{"invalid byte", "a\xffb", "a�b", 3},
+		{"truncated sequence", "a\xe2\x82", "a�", 2},
+		{"surrogate half", "\xed\xa0\x80", "�", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, length := godotStringRoundTrip(test.value)
+			if got != test.want {
+				t.Errorf("round trip = %q; want %q", got, test.want)
+			}
+			if length != test.length {
+				t.Errorf("godot_string length = %d; want %d", length, test.length)
+			}
+		})
+	}
+}
+
+func TestStringVariantRoundTrip(t *testing.T) {
+	checkLeaks(t)
+
+	for _, value := range []string{"", "plain", "𝄞🎮", "mixed ñ 日 😀"} {
+		variant := NewVariantWithString(String(value))
+		if got := string(variant.AsString()); got != value {
+			t.Errorf("Variant round trip = %q; want %q", got, value)
+		}
+		variant.Destroy()
+	}
+}
@@ -0,0 +1,413 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+package gdnative
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// Typed views
+//
+// TypedArray and TypedDictionary are views over an Array or a Dictionary
+// whose elements are all of the given Go types. They never copy the Godot
+// container, elements are converted with the same rules as Marshal and
+// Unmarshal every time they are read or written, so a Variant holding a
+// value of an unexpected type is reported as an UnmarshalTypeError.
+//
+// Elements of builtin types that hold engine memory (Variant, Array,
+// Dictionary...) are owned by the caller that reads them and must be freed
+// as any other owned builtin.
+//
+// Exported methods and properties can declare their parameters as typed
+// views instead of Go slices and maps to access the values passed by Godot
+// without copying them. Changes made through the view are visible to Godot,
+// the view itself is freed once the method returns so a method that keeps it
+// must keep a new reference to its container (see NewArrayCopy).
+//
+// Iterators report conversion errors through the error pointer given to
+// them, every iteration has its own so views can be iterated concurrently or
+// in nested loops.
+
+var (
+	// ErrIndexOutOfRange is returned when accessing a TypedArray out of its bounds
+	ErrIndexOutOfRange = errors.New("index out of range")
+
+	// ErrKeyNotFound is returned when getting a key that a TypedDictionary does not have
+	ErrKeyNotFound = errors.New("key not found")
+)
+
+// toVariant converts the given typed element into a new Variant owned by the caller
+func toVariant[T any](value T) (Variant, error) {
+	return marshalValue(reflect.ValueOf(&value).Elem())
+}
+
+// fromVariant converts the given Variant into a typed element, field is
+// used to locate the element in error messages
+func fromVariant[T any](variant Variant, field string) (T, error) {
+	var value T
+	err := unmarshalValue(variant, reflect.ValueOf(&value).Elem(), field)
+	return value, err
+}
+
+// setIterationError stores the given error in the error pointer given to an
+// iterator unless it is nil
+func setIterationError(dst *error, err error) {
+	if dst != nil {
+		*dst = err
+	}
+}
+
+// TypedArray is a view over an Array whose elements are all of type T
+type TypedArray[T any] struct {
+	array Array
+}
+
+// NewTypedArray creates a new empty TypedArray owned by the caller
+func NewTypedArray[T any]() TypedArray[T] {
+	return TypedArray[T]{array: NewArray()}
+}
+
+// NewTypedArrayFromArray returns back a TypedArray view over the given
+// Array, the view shares the Array contents and its ownership
+func NewTypedArrayFromArray[T any](array Array) TypedArray[T] {
+	return TypedArray[T]{array: array}
+}
+
+// Array returns back the Array this view is over
+func (gdt *TypedArray[T]) Array() Array {
+	return gdt.array
+}
+
+// Free releases the Array this view is over, see Array.Free
+func (gdt *TypedArray[T]) Free() {
+	gdt.array.Free()
+}
+
+// Len returns back the number of elements in the array
+func (gdt *TypedArray[T]) Len() int {
+	return int(gdt.array.Size())
+}
+
+// Get returns back the element at the given index
+func (gdt *TypedArray[T]) Get(index int) (T, error) {
+
+	if err := gdt.checkIndex(index); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	element := gdt.array.Get(Int(index))
+	defer element.Destroy()
+
+	return fromVariant[T](element, fmt.Sprintf("[%d]", index))
+}
+
+// Set replaces the element at the given index
+func (gdt *TypedArray[T]) Set(index int, value T) error {
+
+	if err := gdt.checkIndex(index); err != nil {
+		return err
+	}
+
+	element, err := toVariant(value)
+	if err != nil {
+		return err
+	}
+	defer element.Destroy()
+
+	gdt.array.Set(Int(index), element)
+	return nil
+}
+
+// Append adds the given value at the end of the array
+func (gdt *TypedArray[T]) Append(value T) error {
+
+	element, err := toVariant(value)
+	if err != nil {
+		return err
+	}
+	defer element.Destroy()
+
+	gdt.array.Append(element)
+	return nil
+}
+
+// All returns back an iterator over the indexes and elements of the array,
+// the iteration stops at the first element that can not be converted and
+// the error is stored in err unless it is nil
+func (gdt *TypedArray[T]) All(err *error) iter.Seq2[int, T] {
+
+	return func(yield func(int, T) bool) {
+
+		setIterationError(err, nil)
+		for i := 0; i < gdt.Len(); i++ {
+			value, getErr := gdt.Get(i)
+			if getErr != nil {
+				setIterationError(err, getErr)
+				return
+			}
+
+			if !yield(i, value) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns back an iterator over the elements of the array, see All
+func (gdt *TypedArray[T]) Values(err *error) iter.Seq[T] {
+
+	return func(yield func(T) bool) {
+		for _, value := range gdt.All(err) {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalVariant implements VariantMarshaler
+func (gdt TypedArray[T]) MarshalVariant() (Variant, error) {
+	return NewVariantArray(gdt.array), nil
+}
+
+// UnmarshalVariant implements VariantUnmarshaler, the view is over a new
+// reference to the Array held by the given Variant, its elements are not
+// copied nor checked until they are accessed. Pool*Arrays are rejected as
+// Godot would copy them into a new Array and changes made through the view
+// would be lost, use a Go slice for them instead
+func (gdt *TypedArray[T]) UnmarshalVariant(variant Variant) error {
+
+	if variant.GetType() != VariantTypeArray {
+		return fmt.Errorf("expected %s", variantTypeName(VariantTypeArray))
+	}
+
+	gdt.array = variant.AsArray()
+	return nil
+}
+
+// checkIndex returns an error if the given index is out of the array bounds
+func (gdt *TypedArray[T]) checkIndex(index int) error {
+
+	if length := gdt.Len(); index < 0 || index >= length {
+		return fmt.Errorf("gdnative: %w [%d] with length %d", ErrIndexOutOfRange, index, length)
+	}
+
+	return nil
+}
+
+// TypedDictionary is a view over a Dictionary whose keys are all of type K
+// and whose values are all of type V
+type TypedDictionary[K, V any] struct {
+	dictionary Dictionary
+}
+
+// NewTypedDictionary creates a new empty TypedDictionary owned by the caller
+func NewTypedDictionary[K, V any]() TypedDictionary[K, V] {
+	return TypedDictionary[K, V]{dictionary: NewDictionary()}
+}
+
+// NewTypedDictionaryFromDictionary returns back a TypedDictionary view over
+// the given Dictionary, the view shares the Dictionary contents and its
+// ownership
+func NewTypedDictionaryFromDictionary[K, V any](dictionary Dictionary) TypedDictionary[K, V] {
+	return TypedDictionary[K, V]{dictionary: dictionary}
+}
+
+// Dictionary returns back the Dictionary this view is over
+func (gdt *TypedDictionary[K, V]) Dictionary() Dictionary {
+	return gdt.dictionary
+}
+
+// Free releases the Dictionary this view is over, see Dictionary.Free
+func (gdt *TypedDictionary[K, V]) Free() {
+	gdt.dictionary.Free()
+}
+
+// Len returns back the number of entries in the dictionary
+func (gdt *TypedDictionary[K, V]) Len() int {
+	return int(gdt.dictionary.Size())
+}
+
+// Has returns true if the dictionary has the given key
+func (gdt *TypedDictionary[K, V]) Has(key K) (bool, error) {
+
+	variantKey, err := toVariant(key)
+	if err != nil {
+		return false, err
+	}
+	defer variantKey.Destroy()
+
+	return bool(gdt.dictionary.Has(variantKey)), nil
+}
+
+// Get returns back the value stored under the given key, ErrKeyNotFound is
+// returned if the dictionary does not have it
+func (gdt *TypedDictionary[K, V]) Get(key K) (V, error) {
+
+	var zero V
+	variantKey, err := toVariant(key)
+	if err != nil {
+		return zero, err
+	}
+	defer variantKey.Destroy()
+
+	if !bool(gdt.dictionary.Has(variantKey)) {
+		return zero, fmt.Errorf("gdnative: %w: %v", ErrKeyNotFound, key)
+	}
+
+	element := gdt.dictionary.Get(variantKey)
+	defer element.Destroy()
+
+	return fromVariant[V](element, fmt.Sprintf("[%v]", key))
+}
+
+// Set stores the given value under the given key
+func (gdt *TypedDictionary[K, V]) Set(key K, value V) error {
+
+	variantKey, err := toVariant(key)
+	if err != nil {
+		return err
+	}
+	defer variantKey.Destroy()
+
+	element, err := toVariant(value)
+	if err != nil {
+		return err
+	}
+	defer element.Destroy()
+
+	gdt.dictionary.Set(variantKey, element)
+	return nil
+}
+
+// Erase removes the given key from the dictionary, erasing a key that the
+// dictionary does not have does nothing
+func (gdt *TypedDictionary[K, V]) Erase(key K) error {
+
+	variantKey, err := toVariant(key)
+	if err != nil {
+		return err
+	}
+	defer variantKey.Destroy()
+
+	gdt.dictionary.Erase(variantKey)
+	return nil
+}
+
+// All returns back an iterator over the keys and values of the dictionary
+// in insertion order, the iteration stops at the first entry that can not
+// be converted and the error is stored in err unless it is nil
+func (gdt *TypedDictionary[K, V]) All(err *error) iter.Seq2[K, V] {
+
+	return func(yield func(K, V) bool) {
+
+		setIterationError(err, nil)
+		keys := gdt.dictionary.Keys()
+		defer keys.Destroy()
+
+		for i := 0; i < int(keys.Size()); i++ {
+			key, value, entryErr := gdt.entry(keys, i)
+			if entryErr != nil {
+				setIterationError(err, entryErr)
+				return
+			}
+
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns back an iterator over the keys of the dictionary, values
+// are not converted, see All
+func (gdt *TypedDictionary[K, V]) Keys(err *error) iter.Seq[K] {
+
+	return func(yield func(K) bool) {
+
+		setIterationError(err, nil)
+		keys := gdt.dictionary.Keys()
+		defer keys.Destroy()
+
+		for i := 0; i < int(keys.Size()); i++ {
+			variantKey := keys.Get(Int(i))
+			key, keyErr := fromVariant[K](variantKey, "[key]")
+			variantKey.Destroy()
+			if keyErr != nil {
+				setIterationError(err, keyErr)
+				return
+			}
+
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns back an iterator over the values of the dictionary, see All
+func (gdt *TypedDictionary[K, V]) Values(err *error) iter.Seq[V] {
+
+	return func(yield func(V) bool) {
+		for _, value := range gdt.All(err) {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalVariant implements VariantMarshaler
+func (gdt TypedDictionary[K, V]) MarshalVariant() (Variant, error) {
+	return NewVariantDictionary(gdt.dictionary), nil
+}
+
+// UnmarshalVariant implements VariantUnmarshaler, the view is over a new
+// reference to the Dictionary held by the given Variant, its entries are
+// not copied nor checked until they are accessed
+func (gdt *TypedDictionary[K, V]) UnmarshalVariant(variant Variant) error {
+
+	if variant.GetType() != VariantTypeDictionary {
+		return fmt.Errorf("expected %s", variantTypeName(VariantTypeDictionary))
+	}
+
+	gdt.dictionary = variant.AsDictionary()
+	return nil
+}
+
+// entry returns back the converted key and value at the given position of
+// the given keys Array
+func (gdt *TypedDictionary[K, V]) entry(keys Array, i int) (K, V, error) {
+
+	var value V
+	variantKey := keys.Get(Int(i))
+	defer variantKey.Destroy()
+
+	key, err := fromVariant[K](variantKey, "[key]")
+	if err != nil {
+		return key, value, err
+	}
+
+	element := gdt.dictionary.Get(variantKey)
+	defer element.Destroy()
+
+	value, err = fromVariant[V](element, fmt.Sprintf("[%v]", key))
+	return key, value, err
+}
@@ -0,0 +1,244 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+//go:build fakeapi
+
+package gdnative
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTypedArray(t *testing.T) {
+	checkLeaks(t)
+
+	array := NewTypedArray[int]()
+	defer array.Free()
+
+	for _, value := range []int{1, 2, 3} {
+		if err := array.Append(value); err != nil {
+			t.Fatalf("Append(%d): %v", value, err)
+		}
+	}
+
+	if err := array.Set(1, 20); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if value, err := array.Get(1); err != nil || value != 20 {
+		t.Errorf("Get(1) = %d, %v; want 20, nil", value, err)
+	}
+
+	var err error
+	values := slices.Collect(array.Values(&err))
+	if err != nil {
+		t.Fatalf("Values: %v", err)
+	}
+	if want := []int{1, 20, 3}; !slices.Equal(values, want) {
+		t.Errorf("Values = %v; want %v", values, want)
+	}
+
+	for _, index := range []int{-1, 3} {
+		if _, err := array.Get(index); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("Get(%d) error = %v; want ErrIndexOutOfRange", index, err)
+		}
+		if err := array.Set(index, 0); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("Set(%d) error = %v; want ErrIndexOutOfRange", index, err)
+		}
+	}
+}
+
+func TestTypedArrayConversionError(t *testing.T) {
+	checkLeaks(t)
+
+	array := NewArray()
+	defer array.Free()
+
+	for _, variant := range []Variant{NewVariantInt(1), NewVariantWithString("two"), NewVariantInt(3)} {
+		array.Append(variant)
+		variant.Destroy()
+	}
+
+	view := NewTypedArrayFromArray[int](array)
+
+	var (
+		err    error
+		values []int
+	)
+	for i, value := range view.All(&err) {
+		if i != len(values) {
+			t.Errorf("index = %d; want %d", i, len(values))
+		}
+		values = append(values, value)
+	}
+
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("All error = %v; want *UnmarshalTypeError", err)
+	}
+	if want := []int{1}; !slices.Equal(values, want) {
+		t.Errorf("values before the error = %v; want %v", values, want)
+	}
+}
+
+func TestTypedArrayNestedIteration(t *testing.T) {
+	checkLeaks(t)
+
+	numbers := NewTypedArray[int]()
+	defer numbers.Free()
+	numbers.Append(1)
+	numbers.Append(2)
+
+	words := NewTypedArray[string]()
+	defer words.Free()
+	words.Append("a")
+
+	broken := NewTypedArrayFromArray[int](words.Array())
+
+	var outerErr error
+	pairs := 0
+	for range numbers.Values(&outerErr) {
+		var innerErr error
+		for range broken.Values(&innerErr) {
+			t.Error("converted a string element into an int")
+		}
+		if innerErr == nil {
+			t.Error("inner iteration did not report its conversion error")
+		}
+		pairs++
+	}
+
+	if outerErr != nil {
+		t.Errorf("outer iteration error = %v; want nil", outerErr)
+	}
+	if pairs != 2 {
+		t.Errorf("outer iteration yielded %d elements; want 2", pairs)
+	}
+}
+
+func TestTypedArrayUnmarshalVariant(t *testing.T) {
+	checkLeaks(t)
+
+	array := NewArray()
+	defer array.Free()
+
+	variant := NewVariantArray(array)
+	defer variant.Destroy()
+
+	var view TypedArray[string]
+	if err := Unmarshal(variant, &view); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if err := view.Append("shared"); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	view.Free()
+
+	if size := array.Size(); size != 1 {
+		t.Errorf("Array size after appending through the view = %d; want 1", size)
+	}
+
+	pool := NewPoolIntArrayFromInts([]int32{1, 2})
+	defer pool.Destroy()
+
+	poolVariant := NewVariantPoolIntArray(pool)
+	defer poolVariant.Destroy()
+
+	var poolView TypedArray[int]
+	if err := Unmarshal(poolVariant, &poolView); err == nil {
+		poolView.Free()
+		t.Error("Unmarshal of a PoolIntArray into a TypedArray did not fail")
+	}
+}
+
+func TestTypedDictionary(t *testing.T) {
+	checkLeaks(t)
+
+	dictionary := NewTypedDictionary[string, int]()
+	defer dictionary.Free()
+
+	for i, key := range []string{"one", "two", "three"} {
+		if err := dictionary.Set(key, i+1); err != nil {
+			t.Fatalf("Set(%q): %v", key, err)
+		}
+	}
+
+	if value, err := dictionary.Get("two"); err != nil || value != 2 {
+		t.Errorf("Get(two) = %d, %v; want 2, nil", value, err)
+	}
+
+	if _, err := dictionary.Get("four"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Get(four) error = %v; want ErrKeyNotFound", err)
+	}
+
+	if err := dictionary.Erase("two"); err != nil {
+		t.Fatalf("Erase: %v", err)
+	}
+
+	if has, err := dictionary.Has("two"); err != nil || has {
+		t.Errorf("Has(two) after Erase = %t, %v; want false, nil", has, err)
+	}
+
+	if length := dictionary.Len(); length != 2 {
+		t.Errorf("Len = %d; want 2", length)
+	}
+
+	var err error
+	var keys []string
+	var values []int
+	for key, value := range dictionary.All(&err) {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	if err != nil {
+		t.Fatalf("All: %v", err)
+	}
+	if want := []string{"one", "three"}; !slices.Equal(keys, want) {
+		t.Errorf("All keys = %v; want %v", keys, want)
+	}
+	if want := []int{1, 3}; !slices.Equal(values, want) {
+		t.Errorf("All values = %v; want %v", values, want)
+	}
+
+	if got := slices.Collect(dictionary.Keys(&err)); err != nil || !slices.Equal(got, keys) {
+		t.Errorf("Keys = %v, %v; want %v, nil", got, err, keys)
+	}
+	if got := slices.Collect(dictionary.Values(&err)); err != nil || !slices.Equal(got, values) {
+		t.Errorf("Values = %v, %v; want %v, nil", got, err, values)
+	}
+}
+
+func TestTypedDictionaryConversionError(t *testing.T) {
+	checkLeaks(t)
+
+	dictionary := NewTypedDictionary[string, string]()
+	defer dictionary.Free()
+	dictionary.Set("key", "value")
+
+	view := NewTypedDictionaryFromDictionary[int, string](dictionary.Dictionary())
+
+	var err error
+	for range view.Keys(&err) {
+		t.Error("converted a string key into an int")
+	}
+
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("Keys error = %v; want *UnmarshalTypeError", err)
+	}
+}
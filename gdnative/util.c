@@ -1,7 +1,18 @@
+#ifndef _WIN32
+#define _POSIX_C_SOURCE 200809L
+#endif
+
 #include "util.h"
 #include <gdnative/gdnative.h>
+#include <stdint.h>
 #include <stdlib.h>
 
+#ifdef _WIN32
+#include <windows.h>
+#else
+#include <pthread.h>
+#endif
+
 // Helper functions for accessing C arrays.
 godot_gdnative_api_struct *cgo_get_ext(godot_gdnative_api_struct **ext, int i) {
 	return ext[i];
@@ -16,3 +27,21 @@ void **go_void_build_array(int length) {
 void go_void_add_element(void **array, void *element, int index) {
 	array[index] = element;
 }
+
+// Returns an identifier for the OS thread that is running the caller.
+uint64_t cgo_current_thread_id() {
+#ifdef _WIN32
+	return (uint64_t)GetCurrentThreadId();
+#else
+	return (uint64_t)(uintptr_t)pthread_self();
+#endif
+}
+
+// Calls the given class constructor, cgo can not call C function pointers.
+godot_object *cgo_construct_object(godot_class_constructor constructor) {
+	if (constructor == NULL) {
+		return NULL;
+	}
+
+	return constructor();
+}
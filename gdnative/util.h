@@ -1,4 +1,5 @@
 #include <gdnative/gdnative.h>
+#include <stdint.h>
 #include <stdlib.h>
 
 #ifndef CHELPER_H
@@ -6,4 +7,6 @@
 godot_gdnative_api_struct *cgo_get_ext(godot_gdnative_api_struct **ext, int i);
 void **go_void_build_array(int length);
 void go_void_add_element(void **array, void *element, int index);
+uint64_t cgo_current_thread_id();
+godot_object *cgo_construct_object(godot_class_constructor constructor);
 #endif
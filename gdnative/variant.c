@@ -1,5 +1,6 @@
 #include "variant.h"
 #include <gdnative/variant.h>
+#include <gdnative_api_struct.gen.h>
 #include <stdlib.h>
 
 godot_variant **go_godot_variant_build_array(int length) {
@@ -10,8 +11,7 @@ godot_variant **go_godot_variant_build_array(int length) {
 
 void go_godot_variant_add_element(godot_variant **array, godot_variant *element,
 				  int index) {
-	godot_variant copy = *element;
-	array[index] = &copy;
+	array[index] = element;
 }
 
 godot_variant *go_godot_new_variant() {
@@ -19,3 +19,26 @@ godot_variant *go_godot_new_variant() {
 
 	return var;
 }
+
+// Walks the core API versions chain looking for the 1.1 API, returns NULL if
+// the running Godot version does not provide it.
+const godot_gdnative_core_1_1_api_struct *go_godot_core_1_1_api(
+	const godot_gdnative_core_api_struct *api) {
+	const godot_gdnative_api_struct *next = api->next;
+	while (next != NULL) {
+		if (next->version.major == 1 && next->version.minor == 1) {
+			return (const godot_gdnative_core_1_1_api_struct *)next;
+		}
+		next = next->next;
+	}
+
+	return NULL;
+}
+
+// Evaluates the given operator on the given variants storing the result in
+// ret, valid is set to false if the operator is not valid for the operands.
+void go_godot_variant_evaluate(const godot_gdnative_core_1_1_api_struct *api,
+			       godot_variant_operator op, const godot_variant *a,
+			       const godot_variant *b, godot_variant *ret, godot_bool *valid) {
+	api->godot_variant_evaluate(op, a, b, ret, valid);
+}
@@ -8,16 +8,64 @@ package gdnative
 */
 import "C"
 
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+)
+
+// ErrEvaluateUnavailable is returned by Variant.Evaluate when the running
+// Godot version does not provide the GDNative core 1.1 API
+var ErrEvaluateUnavailable = errors.New("gdnative: Variant.Evaluate requires the GDNative core 1.1 API")
+
+// OperatorError is returned by Variant.Evaluate when Godot can not apply the
+// operator to the given operands
+type OperatorError struct {
+	Operator VariantOperator
+	Left     VariantType
+	Right    VariantType
+}
+
+// Error implements the error interface
+func (e *OperatorError) Error() string {
+	return fmt.Sprintf(
+		"gdnative: invalid operands %s and %s for operator %s",
+		variantTypeName(e.Left), variantTypeName(e.Right), variantOperatorName(e.Operator),
+	)
+}
+
+// variantOperatorName returns back a human readable name for the given VariantOperator
+func variantOperatorName(operator VariantOperator) string {
+
+	for name, value := range VariantOperatorLookupMap {
+		if value == operator {
+			return strings.TrimPrefix(name, "VariantOp")
+		}
+	}
+
+	return fmt.Sprintf("VariantOperator(%d)", int(operator))
+}
+
 // NewVariantWithString creates a new Variant initialized with the given string
 func NewVariantWithString(str String) Variant {
 	var variant C.godot_variant
-	C.go_godot_variant_new_string(GDNative.api, &variant, str.getBase())
+	base := str.getBase()
+	defer C.go_godot_string_destroy(GDNative.api, base)
+	C.go_godot_variant_new_string(GDNative.api, &variant, base)
 
-	return Variant{base: &variant}
+	return newOwnedVariant(&variant)
 }
 
-// GetType returns back the VaraintType for this Variant
+// GetType returns back the VaraintType for this Variant, a zero Variant is
+// of type Nil
 func (gdt *Variant) GetType() VariantType {
+	if gdt.base == nil {
+		return VariantTypeNil
+	}
+
 	variantType := C.go_godot_variant_get_type(GDNative.api, gdt.getBase())
 	return VariantType(variantType)
 }
@@ -35,3 +83,206 @@ func (gdt *VariantArray) getBase() **C.godot_variant {
 
 	return variantArray
 }
+
+// operand returns back the base of this Variant to be given to Godot as an
+// operand, a zero Variant is given as a temporary Nil Variant that is
+// destroyed by the returned function
+func (gdt Variant) operand() (*C.godot_variant, func()) {
+
+	if gdt.base != nil {
+		return gdt.base, func() {}
+	}
+
+	var nilVariant C.godot_variant
+	C.go_godot_variant_new_nil(GDNative.api, &nilVariant)
+	return &nilVariant, func() { C.go_godot_variant_destroy(GDNative.api, &nilVariant) }
+}
+
+// Equal returns true if this Variant is equal to the given one using Godot's
+// == operator, numbers of different types compare by value. Zero Variants
+// are compared as Nil
+func (gdt *Variant) Equal(other Variant) bool {
+	left, destroyLeft := gdt.operand()
+	defer destroyLeft()
+	right, destroyRight := other.operand()
+	defer destroyRight()
+
+	return bool(C.go_godot_variant_operator_equal(GDNative.api, left, right))
+}
+
+// Less returns true if this Variant is less than the given one using Godot's
+// < operator. Zero Variants are compared as Nil
+func (gdt *Variant) Less(other Variant) bool {
+	left, destroyLeft := gdt.operand()
+	defer destroyLeft()
+	right, destroyRight := other.operand()
+	defer destroyRight()
+
+	return bool(C.go_godot_variant_operator_less(GDNative.api, left, right))
+}
+
+// Evaluate applies the given operator to this Variant and the given one and
+// returns a new Variant owned by the caller, as GDScript would do for
+// `variant op other`. Unary operators ignore the other operand. It returns an
+// *OperatorError if the operator is not valid for the operands
+func (gdt *Variant) Evaluate(op VariantOperator, other Variant) (Variant, error) {
+	GDNative.checkInit()
+	if GDNative.api11 == nil {
+		return NewVariantNil(), ErrEvaluateUnavailable
+	}
+
+	left, destroyLeft := gdt.operand()
+	defer destroyLeft()
+	right, destroyRight := other.operand()
+	defer destroyRight()
+
+	var result C.godot_variant
+	var valid C.godot_bool
+	C.go_godot_variant_evaluate(GDNative.api11, op.getBase(), left, right, &result, &valid)
+	if !bool(valid) {
+		C.go_godot_variant_destroy(GDNative.api, &result)
+		return NewVariantNil(), &OperatorError{Operator: op, Left: gdt.GetType(), Right: other.GetType()}
+	}
+
+	return newOwnedVariant(&result), nil
+}
+
+// Hash returns back a 64 bits FNV-1a hash of this Variant. Variants that are
+// Equal hash to the same value: ints and floats are hashed by their numeric
+// value, Strings and NodePaths by their text and Arrays by the hashes of
+// their elements. Dictionaries and pool arrays are hashed by their type and
+// size only so hashing them does not walk nor copy their contents, any other
+// value is hashed by its type and its string form. A zero Variant hashes as
+// Nil.
+//
+// GDNative does not expose Godot's Variant hash (godot_variant_hash_compare
+// only tells whether two Variants are equal) and Godot's own Array and String
+// hashes tell ints and floats apart, so Equal values would not hash alike
+func (gdt *Variant) Hash() uint64 {
+
+	hash := fnv.New64a()
+	kind := gdt.GetType()
+	switch kind {
+	case VariantTypeNil:
+		hash.Write([]byte{byte(VariantTypeNil)})
+	case VariantTypeInt, VariantTypeReal:
+		value := float64(gdt.AsReal())
+		switch {
+		case value == 0:
+			// -0 and 0 are equal
+			value = 0
+		case math.IsNaN(value):
+			value = math.NaN()
+		}
+		var buffer [8]byte
+		binary.LittleEndian.PutUint64(buffer[:], math.Float64bits(value))
+		hash.Write([]byte{byte(VariantTypeReal)})
+		hash.Write(buffer[:])
+	case VariantTypeString, VariantTypeNodePath:
+		hash.Write([]byte{byte(VariantTypeString)})
+		hash.Write([]byte(gdt.String()))
+	case VariantTypeArray:
+		array := gdt.AsArray()
+		defer array.Destroy()
+
+		var buffer [8]byte
+		hash.Write([]byte{byte(kind)})
+		for i := 0; i < int(array.Size()); i++ {
+			element := array.Get(Int(i))
+			binary.LittleEndian.PutUint64(buffer[:], element.Hash())
+			element.Destroy()
+			hash.Write(buffer[:])
+		}
+	case VariantTypeDictionary, VariantTypePoolByteArray, VariantTypePoolIntArray,
+		VariantTypePoolRealArray, VariantTypePoolStringArray, VariantTypePoolVector2Array,
+		VariantTypePoolVector3Array, VariantTypePoolColorArray:
+		var buffer [8]byte
+		binary.LittleEndian.PutUint64(buffer[:], uint64(gdt.containerSize()))
+		hash.Write([]byte{byte(kind)})
+		hash.Write(buffer[:])
+	default:
+		hash.Write([]byte{byte(kind)})
+		hash.Write([]byte(gdt.String()))
+	}
+
+	return hash.Sum64()
+}
+
+// containerSize returns back the number of elements of the Dictionary or
+// pool array held by this Variant
+func (gdt *Variant) containerSize() Int {
+
+	switch gdt.GetType() {
+	case VariantTypeDictionary:
+		dictionary := gdt.AsDictionary()
+		defer dictionary.Destroy()
+		return dictionary.Size()
+	case VariantTypePoolByteArray:
+		pool := gdt.AsPoolByteArray()
+		defer pool.Destroy()
+		return pool.Size()
+	case VariantTypePoolIntArray:
+		pool := gdt.AsPoolIntArray()
+		defer pool.Destroy()
+		return pool.Size()
+	case VariantTypePoolRealArray:
+		pool := gdt.AsPoolRealArray()
+		defer pool.Destroy()
+		return pool.Size()
+	case VariantTypePoolStringArray:
+		pool := gdt.AsPoolStringArray()
+		defer pool.Destroy()
+		return pool.Size()
+	case VariantTypePoolVector2Array:
+		pool := gdt.AsPoolVector2Array()
+		defer pool.Destroy()
+		return pool.Size()
+	case VariantTypePoolVector3Array:
+		pool := gdt.AsPoolVector3Array()
+		defer pool.Destroy()
+		return pool.Size()
+	case VariantTypePoolColorArray:
+		pool := gdt.AsPoolColorArray()
+		defer pool.Destroy()
+		return pool.Size()
+	}
+
+	return 0
+}
+
+// String implements fmt.Stringer returning the same text that GDScript's
+// str() returns for this Variant, a zero Variant is printed as Null
+func (gdt Variant) String() string {
+
+	if gdt.base == nil {
+		return "Null"
+	}
+
+	return string(gdt.AsString())
+}
+
+// DeepCopy returns back a new Variant owned by the caller, Arrays and
+// Dictionaries are duplicated recursively so the copy does not share any
+// container with this Variant. Objects are not duplicated, the copy points to
+// the same Object. A zero Variant is copied into a Nil Variant
+func (gdt *Variant) DeepCopy() Variant {
+
+	switch gdt.GetType() {
+	case VariantTypeArray, VariantTypeDictionary:
+		deep := NewVariantBool(true)
+		defer deep.Destroy()
+
+		duplicate, err := callVariant(gdt.getBase(), "duplicate", []Variant{deep})
+		if err == nil {
+			return duplicate
+		}
+		duplicate.Destroy()
+	}
+
+	if gdt.base == nil {
+		return NewVariantNil()
+	}
+
+	// any other Variant holds a value type or a copy on write pool array
+	return NewVariantCopy(*gdt)
+}
@@ -2,10 +2,18 @@
 #define CGDNATIVE_VARIANT_H
 
 #include <gdnative/variant.h>
+#include <gdnative_api_struct.gen.h>
 #include <stdlib.h>
 
 godot_variant **go_godot_variant_build_array(int);
 void go_godot_variant_add_element(godot_variant **, godot_variant *, int);
 godot_variant *go_godot_new_variant();
 
+/* GDNative core 1.1 API */
+const godot_gdnative_core_1_1_api_struct *go_godot_core_1_1_api(
+	const godot_gdnative_core_api_struct *api);
+void go_godot_variant_evaluate(const godot_gdnative_core_1_1_api_struct *api,
+			       godot_variant_operator op, const godot_variant *a,
+			       const godot_variant *b, godot_variant *ret, godot_bool *valid);
+
 #endif
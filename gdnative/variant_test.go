@@ -0,0 +1,123 @@
+// Copyright © 2019 - 2020 Oscar Campos <oscar.campos@thepimpam.com>
+// Copyright © 2017 - William Edwards
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License
+
+//go:build fakeapi
+
+package gdnative
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVariantZeroValue(t *testing.T) {
+	checkLeaks(t)
+
+	var zero Variant
+	null := NewVariantNil()
+	defer null.Destroy()
+	number := NewVariantInt(1)
+	defer number.Destroy()
+
+	if kind := zero.GetType(); kind != VariantTypeNil {
+		t.Errorf("GetType = %s; want Nil", variantTypeName(kind))
+	}
+	if !zero.Equal(null) || !null.Equal(zero) || !zero.Equal(Variant{}) {
+		t.Error("a zero Variant is not Equal to Nil")
+	}
+	if zero.Equal(number) {
+		t.Error("a zero Variant is Equal to 1")
+	}
+	if zero.Less(null) != null.Less(null) || zero.Less(number) != null.Less(number) || number.Less(zero) != number.Less(null) {
+		t.Error("a zero Variant is not ordered as Nil")
+	}
+	if zero.Hash() != null.Hash() {
+		t.Error("a zero Variant does not hash as Nil")
+	}
+	if str := zero.String(); str != "Null" {
+		t.Errorf("String = %q; want Null", str)
+	}
+
+	copied := zero.DeepCopy()
+	defer copied.Destroy()
+	if kind := copied.GetType(); kind != VariantTypeNil {
+		t.Errorf("DeepCopy type = %s; want Nil", variantTypeName(kind))
+	}
+}
+
+func TestVariantHash(t *testing.T) {
+	checkLeaks(t)
+
+	newArray := func(elements ...Variant) Variant {
+		array := NewArray()
+		defer array.Destroy()
+		for _, element := range elements {
+			array.Append(element)
+			element.Destroy()
+		}
+		return NewVariantArray(array)
+	}
+
+	newPool := func(values ...int32) Variant {
+		pool := NewPoolIntArrayFromInts(values)
+		defer pool.Destroy()
+		return NewVariantPoolIntArray(pool)
+	}
+
+	tests := []struct {
+		name        string
+		left, right func() Variant
+		equal       bool
+	}{
+		{"int and real", func() Variant { return NewVariantInt(1) }, func() Variant { return NewVariantReal(1) }, true},
+		{"zero and negative zero", func() Variant { return NewVariantReal(0) }, func() Variant { return NewVariantReal(Double(math.Copysign(0, -1))) }, true},
+		{"NaN", func() Variant { return NewVariantReal(Double(math.NaN())) }, func() Variant { return NewVariantReal(Double(math.NaN())) }, true},
+		{"different numbers", func() Variant { return NewVariantInt(1) }, func() Variant { return NewVariantInt(2) }, false},
+		{"strings", func() Variant { return NewVariantWithString("ü𝄞") }, func() Variant { return NewVariantWithString("ü𝄞") }, true},
+		{"string and number", func() Variant { return NewVariantWithString("1") }, func() Variant { return NewVariantInt(1) }, false},
+		{
+			"arrays with equal numbers",
+			func() Variant { return newArray(NewVariantInt(1), NewVariantWithString("a")) },
+			func() Variant { return newArray(NewVariantReal(1), NewVariantWithString("a")) },
+			true,
+		},
+		{
+			"arrays with different elements",
+			func() Variant { return newArray(NewVariantInt(1)) },
+			func() Variant { return newArray(NewVariantInt(2)) },
+			false,
+		},
+		{
+			"nested arrays",
+			func() Variant { return newArray(newArray(NewVariantInt(1))) },
+			func() Variant { return newArray(newArray(NewVariantReal(1))) },
+			true,
+		},
+		{"pools of the same size", func() Variant { return newPool(1, 2) }, func() Variant { return newPool(1, 2) }, true},
+		{"pools of different sizes", func() Variant { return newPool(1) }, func() Variant { return newPool(1, 2) }, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			left, right := test.left(), test.right()
+			defer left.Destroy()
+			defer right.Destroy()
+
+			if equal := left.Hash() == right.Hash(); equal != test.equal {
+				t.Errorf("equal hashes = %t; want %t", equal, test.equal)
+			}
+		})
+	}
+}
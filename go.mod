@@ -8,4 +8,4 @@ require (
 	github.com/vitaminwater/cgo.wchar v0.0.0-20160320123332-5dd6f4be3f2a
 )
 
-go 1.14
+go 1.23
@@ -103,6 +103,11 @@ func RetrieveGodotDocumentation() error {
 	return nil
 }
 
+// Test runs the tests, the ones calling into Godot run against the fake core API
+func Test() error {
+	return sh.RunV("go", "test", "-tags", "fakeapi", "-race", "./...")
+}
+
 // Build builds the gdnative-go compiler gogdc (also builds the library)
 func Build() error {
 	return sh.RunWith(flagEnv(), "go", "build", "-ldflags", ldflags, "-x", "./cmd/gogdc")